@@ -1,12 +1,25 @@
 package annotations
 
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
 type annotationsFilter interface {
 	filter(ann []Annotation, chain *annotationsFilterChain) []Annotation
+	name() string
 }
 
 type annotationsFilterChain struct {
 	index   int
 	filters []annotationsFilter
+	// received holds the number of annotations passed to each filter, used to count the dropped ones
+	received []int
+	// ctx is the parent of the spans tracing the filters
+	ctx   context.Context
+	spans []trace.Span
 }
 
 func newAnnotationsFilterChain(filters ...annotationsFilter) *annotationsFilterChain {
@@ -14,12 +27,35 @@ func newAnnotationsFilterChain(filters ...annotationsFilter) *annotationsFilterC
 	f := make([]annotationsFilter, size+1)
 	copy(f, filters)
 	f[size] = defaultDedupFilter
-	return &annotationsFilterChain{0, f}
+	return &annotationsFilterChain{
+		filters:  f,
+		received: make([]int, size+1),
+		ctx:      context.Background(),
+		spans:    make([]trace.Span, size+1),
+	}
+}
+
+// run passes the annotations through the whole chain, tracing every filter in a child span of ctx.
+func (chain *annotationsFilterChain) run(ctx context.Context, ann []Annotation) []Annotation {
+	chain.ctx = ctx
+	return chain.doNext(ann)
 }
 
 func (chain *annotationsFilterChain) doNext(ann []Annotation) []Annotation {
+	if chain.index > 0 {
+		prev := chain.index - 1
+		if dropped := chain.received[prev] - len(ann); dropped > 0 {
+			annotationsDropped.WithLabelValues(chain.filters[prev].name()).Add(float64(dropped))
+		}
+		chain.spans[prev].SetAttributes(attribute.Int(annotationsCountAttribute, len(ann)))
+		chain.spans[prev].End()
+	}
+
 	if chain.index < len(chain.filters) {
 		f := chain.filters[chain.index]
+		chain.received[chain.index] = len(ann)
+		_, chain.spans[chain.index] = startSpan(chain.ctx, "annotationsFilter."+f.name(),
+			trace.WithAttributes(attribute.Int(annotationsInAttribute, len(ann))))
 		chain.index++
 
 		ann = f.filter(ann, chain)
@@ -33,6 +69,10 @@ type dedupFilter struct {
 
 var defaultDedupFilter = &dedupFilter{}
 
+func (f *dedupFilter) name() string {
+	return "dedup"
+}
+
 func (f *dedupFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
 	var out []Annotation
 
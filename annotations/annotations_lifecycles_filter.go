@@ -1,24 +1,140 @@
 package annotations
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
 const (
 	pacLifecycle = "annotations-pac"
 	v2Lifecycle  = "annotations-v2"
 )
 
-var lifecycleMap = map[string]string{
-	"next-video": "annotations-next-video",
-	"v1":         "annotations-v1",
-	"pac":        "annotations-pac",
-	"v2":         "annotations-v2",
-	"manual":     "annotations-manual",
+// LifecyclePolicy is the registry of the annotation lifecycles and the precedence between them.
+type LifecyclePolicy struct {
+	// Lifecycles maps the names accepted by the lifecycle query parameter to the lifecycles of the annotations.
+	Lifecycles map[string]string `yaml:"lifecycles" json:"lifecycles"`
+	// Precedence rules are applied in order. A rule applies if annotations of its lifecycle are present,
+	// and then suppresses the annotations of the other lifecycles it lists, or all but the ones it keeps.
+	Precedence []PrecedenceRule `yaml:"precedence" json:"precedence"`
+}
+
+// PrecedenceRule makes the annotations of a lifecycle suppress the annotations of other lifecycles, either the ones
+// it suppresses or all but the ones it keeps, including the annotations of unregistered lifecycles or without any.
+// Lifecycles are referenced by their names in the registry.
+type PrecedenceRule struct {
+	Lifecycle  string   `yaml:"lifecycle" json:"lifecycle"`
+	Suppresses []string `yaml:"suppresses" json:"suppresses,omitempty"`
+	Keeps      []string `yaml:"keeps" json:"keeps,omitempty"`
+}
+
+// DefaultLifecyclePolicy is used unless another policy is configured: curated (PAC) annotations are returned
+// combined with the v2 annotations, suppressing the annotations of all other lifecycles.
+var DefaultLifecyclePolicy = LifecyclePolicy{
+	Lifecycles: map[string]string{
+		"next-video": "annotations-next-video",
+		"v1":         "annotations-v1",
+		"pac":        pacLifecycle,
+		"v2":         v2Lifecycle,
+		"manual":     "annotations-manual",
+	},
+	Precedence: []PrecedenceRule{
+		{Lifecycle: "pac", Keeps: []string{"v2"}},
+	},
+}
+
+// LoadLifecyclePolicy reads the lifecycle policy from a YAML or JSON file like
+//
+//	lifecycles:
+//	  pac: annotations-pac
+//	  v1: annotations-v1
+//	  v2: annotations-v2
+//	precedence:
+//	  - lifecycle: pac
+//	    suppresses: [v1]
+//	  - lifecycle: v2
+//	    keeps: [pac]
+func LoadLifecyclePolicy(path string) (*LifecyclePolicy, error) {
+	var policy LifecyclePolicy
+	if err := decodeConfigFile(path, "lifecycle policy", &policy); err != nil {
+		return nil, err
+	}
+
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle policy file %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+func (p *LifecyclePolicy) validate() error {
+	if len(p.Lifecycles) == 0 {
+		return errors.New("no lifecycles defined")
+	}
+
+	names := make(map[string]string)
+	for name, lifecycle := range p.Lifecycles {
+		if name == "" || lifecycle == "" {
+			return fmt.Errorf("lifecycle %q has an empty name or value", name)
+		}
+		if other, ok := names[lifecycle]; ok {
+			return fmt.Errorf("lifecycles %s and %s are both mapped to %s", min(name, other), max(name, other), lifecycle)
+		}
+		names[lifecycle] = name
+	}
+
+	ruled := make(map[string]bool)
+	for i, rule := range p.Precedence {
+		if _, ok := p.Lifecycles[rule.Lifecycle]; !ok {
+			return fmt.Errorf("precedence rule %d is for unknown lifecycle %q", i+1, rule.Lifecycle)
+		}
+		if ruled[rule.Lifecycle] {
+			return fmt.Errorf("lifecycle %s has more than one precedence rule", rule.Lifecycle)
+		}
+		ruled[rule.Lifecycle] = true
+
+		switch {
+		case len(rule.Suppresses) > 0 && len(rule.Keeps) > 0:
+			return fmt.Errorf("precedence rule %d for lifecycle %s both suppresses and keeps lifecycles", i+1, rule.Lifecycle)
+		case len(rule.Suppresses) == 0 && len(rule.Keeps) == 0:
+			return fmt.Errorf("precedence rule %d for lifecycle %s suppresses no lifecycles", i+1, rule.Lifecycle)
+		}
+		for _, suppressed := range rule.Suppresses {
+			if _, ok := p.Lifecycles[suppressed]; !ok {
+				return fmt.Errorf("precedence rule %d for lifecycle %s suppresses unknown lifecycle %q", i+1, rule.Lifecycle, suppressed)
+			}
+			if suppressed == rule.Lifecycle {
+				return fmt.Errorf("precedence rule %d for lifecycle %s suppresses its own lifecycle", i+1, rule.Lifecycle)
+			}
+		}
+		for _, kept := range rule.Keeps {
+			if _, ok := p.Lifecycles[kept]; !ok {
+				return fmt.Errorf("precedence rule %d for lifecycle %s keeps unknown lifecycle %q", i+1, rule.Lifecycle, kept)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateParams checks that all the values of the lifecycle query parameter are in the registry.
+func (p *LifecyclePolicy) validateParams(lifecycleParams []string) error {
+	for _, lp := range lifecycleParams {
+		if _, ok := p.Lifecycles[lp]; !ok {
+			return &invalidQueryParamError{param: "lifecycle", value: lp}
+		}
+	}
+
+	return nil
 }
 
 type lifecycleFilter struct {
+	policy     *LifecyclePolicy
 	lifecycles []string
 }
 
 func newLifecycleFilter(opts ...func(*lifecycleFilter)) *lifecycleFilter {
-	lf := lifecycleFilter{}
+	lf := lifecycleFilter{policy: &DefaultLifecyclePolicy}
 	for _, opt := range opts {
 		opt(&lf)
 	}
@@ -32,13 +148,51 @@ func withLifecycles(lifecycles []string) func(*lifecycleFilter) {
 	}
 }
 
+// withLifecyclePolicy replaces the default lifecycle policy of the filter. A nil policy keeps the default.
+func withLifecyclePolicy(policy *LifecyclePolicy) func(*lifecycleFilter) {
+	return func(f *lifecycleFilter) {
+		if policy != nil {
+			f.policy = policy
+		}
+	}
+}
+
+func (f *lifecycleFilter) name() string {
+	return "lifecycle"
+}
+
 func (f *lifecycleFilter) filter(annotations []Annotation, chain *annotationsFilterChain) []Annotation {
-	if containsPACLifecycle(annotations) {
-		filtered := filterPACAndV2Lifecycles(annotations)
-		return chain.doNext(f.applyAdditionalFiltering(filtered))
+	return chain.doNext(f.applyAdditionalFiltering(f.applyPrecedence(annotations)))
+}
+
+// applyPrecedence applies the precedence rules of the policy in order, each one to the annotations left by the previous rules.
+func (f *lifecycleFilter) applyPrecedence(annotations []Annotation) []Annotation {
+	for _, rule := range f.policy.Precedence {
+		if !containsLifecycle(annotations, f.policy.Lifecycles[rule.Lifecycle]) {
+			continue
+		}
+		lifecyclePrecedenceApplied.WithLabelValues(rule.Lifecycle).Inc()
+
+		var kept []Annotation
+		for _, annotation := range annotations {
+			if !f.suppresses(rule, annotation.Lifecycle) {
+				kept = append(kept, annotation)
+			}
+		}
+		annotations = kept
 	}
+	return annotations
+}
 
-	return chain.doNext(f.applyAdditionalFiltering(annotations))
+// suppresses reports whether the rule suppresses the annotations of the lifecycle.
+func (f *lifecycleFilter) suppresses(rule PrecedenceRule, lifecycle string) bool {
+	if lifecycle == f.policy.Lifecycles[rule.Lifecycle] {
+		return false
+	}
+	if len(rule.Keeps) > 0 {
+		return !slices.ContainsFunc(rule.Keeps, func(name string) bool { return f.policy.Lifecycles[name] == lifecycle })
+	}
+	return slices.ContainsFunc(rule.Suppresses, func(name string) bool { return f.policy.Lifecycles[name] == lifecycle })
 }
 
 func (f *lifecycleFilter) applyAdditionalFiltering(annotations []Annotation) []Annotation {
@@ -49,7 +203,7 @@ func (f *lifecycleFilter) applyAdditionalFiltering(annotations []Annotation) []A
 	var filtered []Annotation
 	for _, annotation := range annotations {
 		for _, lc := range f.lifecycles {
-			if annotation.Lifecycle == lifecycleMap[lc] {
+			if annotation.Lifecycle == f.policy.Lifecycles[lc] {
 				filtered = append(filtered, annotation)
 			}
 		}
@@ -57,21 +211,8 @@ func (f *lifecycleFilter) applyAdditionalFiltering(annotations []Annotation) []A
 	return filtered
 }
 
-func containsPACLifecycle(annotations []Annotation) bool {
-	for _, annotation := range annotations {
-		if annotation.Lifecycle == pacLifecycle {
-			return true
-		}
-	}
-	return false
-}
-
-func filterPACAndV2Lifecycles(annotations []Annotation) []Annotation {
-	var filtered []Annotation
-	for _, annotation := range annotations {
-		if annotation.Lifecycle == pacLifecycle || annotation.Lifecycle == v2Lifecycle {
-			filtered = append(filtered, annotation)
-		}
-	}
-	return filtered
+func containsLifecycle(annotations []Annotation, lifecycle string) bool {
+	return slices.ContainsFunc(annotations, func(annotation Annotation) bool {
+		return annotation.Lifecycle == lifecycle
+	})
 }
@@ -1,6 +1,8 @@
 package annotations
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -129,6 +131,17 @@ func TestFilterOnPACV2Annotations(t *testing.T) {
 	assert.Contains(t, filtered, v2AnnotationB)
 }
 
+func TestFilterOnPACAndUnregisteredLifecycleAnnotations(t *testing.T) {
+	mlAnnotation := Annotation{ID: "1f6c1f62-9a6f-4c6f-8f4f-6f7a4b9b1c2d", Predicate: ABOUT, Lifecycle: "annotations-ml"}
+	noLifecycleAnnotation := Annotation{ID: "5d7e2c1a-3b4f-4e6d-9a8b-7c6d5e4f3a2b", Predicate: MENTIONS}
+	annotations := []Annotation{pacAnnotationA, mlAnnotation, noLifecycleAnnotation, v2AnnotationA}
+	f := newLifecycleFilter()
+	chain := newAnnotationsFilterChain(f)
+	filtered := chain.doNext(annotations)
+
+	assert.Equal(t, []Annotation{pacAnnotationA, v2AnnotationA}, filtered)
+}
+
 func TestFilterOnV1V2Annotations(t *testing.T) {
 	annotations := []Annotation{v1AnnotationA, v1AnnotationB, v2AnnotationA, v2AnnotationB}
 	f := newLifecycleFilter()
@@ -271,3 +284,134 @@ func TestAdditionalFilteringNoPACAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterWithConfiguredLifecyclePolicy(t *testing.T) {
+	mlAnnotation := Annotation{ID: "1f6c1f62-9a6f-4c6f-8f4f-6f7a4b9b1c2d", Predicate: ABOUT, Lifecycle: "annotations-ml"}
+	policy := &LifecyclePolicy{
+		Lifecycles: map[string]string{
+			"pac": pacLifecycle,
+			"ml":  "annotations-ml",
+			"v1":  v1Lifecycle,
+		},
+		Precedence: []PrecedenceRule{
+			{Lifecycle: "pac", Suppresses: []string{"ml"}},
+			{Lifecycle: "ml", Suppresses: []string{"v1"}},
+		},
+	}
+	assert.NoError(t, policy.validate())
+
+	tests := map[string]struct {
+		annotations []Annotation
+		lifecycles  []string
+		expected    []Annotation
+	}{
+		"ml suppresses v1": {
+			annotations: []Annotation{v1AnnotationA, mlAnnotation},
+			expected:    []Annotation{mlAnnotation},
+		},
+		"pac suppresses ml, which then no longer suppresses v1": {
+			annotations: []Annotation{v1AnnotationA, mlAnnotation, pacAnnotationA},
+			expected:    []Annotation{v1AnnotationA, pacAnnotationA},
+		},
+		"additional filtering uses the configured lifecycles": {
+			annotations: []Annotation{v1AnnotationA, mlAnnotation, pacAnnotationA},
+			lifecycles:  []string{"v1"},
+			expected:    []Annotation{v1AnnotationA},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newLifecycleFilter(withLifecyclePolicy(policy), withLifecycles(tc.lifecycles))
+			chain := newAnnotationsFilterChain(f)
+			assert.Equal(t, tc.expected, chain.doNext(tc.annotations))
+		})
+	}
+}
+
+func TestLifecyclePolicyValidateParams(t *testing.T) {
+	assert.NoError(t, DefaultLifecyclePolicy.validateParams([]string{"pac", "v1", "v2", "manual", "next-video"}))
+	assert.EqualError(t, DefaultLifecyclePolicy.validateParams([]string{"pac", "ml"}), "invalid lifecycle value: ml")
+}
+
+func TestLoadLifecyclePolicy(t *testing.T) {
+	tests := map[string]struct {
+		config        string
+		expected      *LifecyclePolicy
+		expectedError string
+	}{
+		"valid policy": {
+			config: "lifecycles:\n  pac: annotations-pac\n  ml: annotations-ml\nprecedence:\n  - lifecycle: pac\n    suppresses: [ml]\n",
+			expected: &LifecyclePolicy{
+				Lifecycles: map[string]string{"pac": "annotations-pac", "ml": "annotations-ml"},
+				Precedence: []PrecedenceRule{{Lifecycle: "pac", Suppresses: []string{"ml"}}},
+			},
+		},
+		"valid policy keeping lifecycles": {
+			config: "lifecycles:\n  pac: annotations-pac\n  v2: annotations-v2\nprecedence:\n  - lifecycle: pac\n    keeps: [v2]\n",
+			expected: &LifecyclePolicy{
+				Lifecycles: map[string]string{"pac": "annotations-pac", "v2": "annotations-v2"},
+				Precedence: []PrecedenceRule{{Lifecycle: "pac", Keeps: []string{"v2"}}},
+			},
+		},
+		"no precedence": {
+			config:   `{"lifecycles": {"v1": "annotations-v1"}}`,
+			expected: &LifecyclePolicy{Lifecycles: map[string]string{"v1": "annotations-v1"}},
+		},
+		"no lifecycles": {
+			config:        "precedence: []\n",
+			expectedError: "no lifecycles defined",
+		},
+		"lifecycle mapped twice": {
+			config:        "lifecycles:\n  pac: annotations-pac\n  curated: annotations-pac\n",
+			expectedError: "lifecycles curated and pac are both mapped to annotations-pac",
+		},
+		"rule for unknown lifecycle": {
+			config:        "lifecycles:\n  v1: annotations-v1\nprecedence:\n  - lifecycle: pac\n    suppresses: [v1]\n",
+			expectedError: `precedence rule 1 is for unknown lifecycle "pac"`,
+		},
+		"rule suppressing unknown lifecycle": {
+			config:        "lifecycles:\n  pac: annotations-pac\nprecedence:\n  - lifecycle: pac\n    suppresses: [v1]\n",
+			expectedError: `precedence rule 1 for lifecycle pac suppresses unknown lifecycle "v1"`,
+		},
+		"rule suppressing its own lifecycle": {
+			config:        "lifecycles:\n  pac: annotations-pac\nprecedence:\n  - lifecycle: pac\n    suppresses: [pac]\n",
+			expectedError: "precedence rule 1 for lifecycle pac suppresses its own lifecycle",
+		},
+		"rule suppressing nothing": {
+			config:        "lifecycles:\n  pac: annotations-pac\nprecedence:\n  - lifecycle: pac\n",
+			expectedError: "precedence rule 1 for lifecycle pac suppresses no lifecycles",
+		},
+		"rule keeping unknown lifecycle": {
+			config:        "lifecycles:\n  pac: annotations-pac\nprecedence:\n  - lifecycle: pac\n    keeps: [v2]\n",
+			expectedError: `precedence rule 1 for lifecycle pac keeps unknown lifecycle "v2"`,
+		},
+		"rule suppressing and keeping": {
+			config:        "lifecycles:\n  pac: annotations-pac\n  v1: annotations-v1\n  v2: annotations-v2\nprecedence:\n  - lifecycle: pac\n    suppresses: [v1]\n    keeps: [v2]\n",
+			expectedError: "precedence rule 1 for lifecycle pac both suppresses and keeps lifecycles",
+		},
+		"two rules for a lifecycle": {
+			config:        "lifecycles:\n  pac: annotations-pac\n  v1: annotations-v1\nprecedence:\n  - lifecycle: pac\n    suppresses: [v1]\n  - lifecycle: pac\n    suppresses: [v1]\n",
+			expectedError: "lifecycle pac has more than one precedence rule",
+		},
+		"unknown field": {
+			config:        "lifecycles:\n  pac: annotations-pac\nprecedences: []\n",
+			expectedError: "field precedences not found",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lifecycles.yml")
+			assert.NoError(t, os.WriteFile(path, []byte(tc.config), 0o600))
+
+			policy, err := LoadLifecyclePolicy(path)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package annotations
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -29,34 +32,115 @@ type PredicateFilter struct {
 	filteredAnnotations map[string][]Annotation
 }
 
-func NewAnnotationsPredicateFilter() *PredicateFilter {
-	return &PredicateFilter{
-		enum: []string{
-			Mentions,
-			MajorMentions,
-			About,
-			IsClassifiedBy,
-			HasBrand,
-			ImplicitlyClassifiedBy,
-			IsPrimarilyClassifiedBy,
-		},
+// PredicateRules defines the groups of predicates to whom Rule of Importance should be applied.
+// Each group contains a list of predicate names in the order of increasing importance.
+type PredicateRules [][]string
+
+// DefaultPredicateRules are the predicate groups used unless other rules are configured.
+// HasBrand is not listed, being the same predicate as IsClassifiedBy.
+var DefaultPredicateRules = PredicateRules{
+	{
+		Mentions,
+		MajorMentions,
+		About,
+	},
+	{
+		ImplicitlyClassifiedBy,
+		IsClassifiedBy,
+		IsPrimarilyClassifiedBy,
+	},
+}
+
+// predicateRulesConfig is the format of the predicate rules file.
+// The groups list the relationship names of the predicates, e.g. MENTIONS or IS_CLASSIFIED_BY.
+type predicateRulesConfig struct {
+	Groups [][]string `yaml:"groups"`
+}
+
+// LoadPredicateRules reads the predicate rules from a YAML or JSON file like
+//
+//	groups:
+//	  - [MENTIONS, MAJOR_MENTIONS, ABOUT]
+//	  - [IMPLICITLY_CLASSIFIED_BY, IS_CLASSIFIED_BY, IS_PRIMARILY_CLASSIFIED_BY]
+func LoadPredicateRules(path string) (PredicateRules, error) {
+	var cfg predicateRulesConfig
+	if err := decodeConfigFile(path, "predicate rules", &cfg); err != nil {
+		return nil, err
+	}
+
+	rules, err := newPredicateRules(cfg.Groups)
+	if err != nil {
+		return nil, fmt.Errorf("invalid predicate rules file %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// newPredicateRules maps groups of relationship names to predicate rules, rejecting empty groups,
+// unknown relationships and predicates listed more than once.
+func newPredicateRules(groups [][]string) (PredicateRules, error) {
+	if len(groups) == 0 {
+		return nil, errors.New("no predicate groups defined")
+	}
+
+	rules := make(PredicateRules, 0, len(groups))
+	listed := make(map[string]string)
+	for i, group := range groups {
+		if len(group) == 0 {
+			return nil, fmt.Errorf("predicate group %d is empty", i+1)
+		}
+
+		predicateGroup := make([]string, 0, len(group))
+		for _, relationship := range group {
+			predicate, ok := predicates[relationship]
+			if !ok {
+				return nil, fmt.Errorf("unknown relationship %q in predicate group %d, expected one of %s", relationship, i+1, strings.Join(knownRelationships(), ", "))
+			}
+			predicate = strings.ToLower(predicate)
+			if prev, ok := listed[predicate]; ok {
+				return nil, fmt.Errorf("relationship %s in predicate group %d has the same predicate as %s, which is already listed", relationship, i+1, prev)
+			}
+			listed[predicate] = relationship
+			predicateGroup = append(predicateGroup, predicate)
+		}
+		rules = append(rules, predicateGroup)
+	}
+
+	return rules, nil
+}
+
+func knownRelationships() []string {
+	relationships := make([]string, 0, len(predicates))
+	for r := range predicates {
+		relationships = append(relationships, r)
+	}
+	sort.Strings(relationships)
+	return relationships
+}
+
+func NewAnnotationsPredicateFilter(opts ...func(*PredicateFilter)) *PredicateFilter {
+	f := &PredicateFilter{
 		// Configure groups of predicates that should be filtered according to their importance.
-		ImportanceRuleConfig: [][]string{
-			{
-				Mentions,
-				MajorMentions,
-				About,
-			},
-			{
-				ImplicitlyClassifiedBy,
-				HasBrand,
-				IsClassifiedBy,
-				IsPrimarilyClassifiedBy,
-			},
-		},
+		ImportanceRuleConfig:  DefaultPredicateRules,
 		filteredAnnotations:   make(map[string][]Annotation),
 		unfilteredAnnotations: make(map[string][]Annotation),
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	for _, group := range f.ImportanceRuleConfig {
+		f.enum = append(f.enum, group...)
+	}
+	return f
+}
+
+// withPredicateRules replaces the default predicate rules of the filter. Nil rules keep the defaults.
+func withPredicateRules(rules PredicateRules) func(*PredicateFilter) {
+	return func(f *PredicateFilter) {
+		if rules != nil {
+			f.ImportanceRuleConfig = rules
+		}
+	}
 }
 
 func (f *PredicateFilter) FilterAnnotations(annotations []Annotation) {
@@ -145,6 +229,10 @@ func (f *PredicateFilter) getImportanceValueForGroupID(predicate string, groupID
 	return -1
 }
 
+func (f *PredicateFilter) name() string {
+	return "predicate"
+}
+
 func (f *PredicateFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
 	f.FilterAnnotations(in)
 	return chain.doNext(f.ProduceResponseList())
@@ -1,9 +1,14 @@
 package annotations
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -194,6 +199,99 @@ func TestFilterForBasicSingleConcept(t *testing.T) {
 	}
 }
 
+func TestFilterWithConfiguredPredicateRules(t *testing.T) {
+	rules, err := newPredicateRules([][]string{{"MENTIONS", "ABOUT", "HAS_DISPLAY_TAG"}})
+	assert.NoError(t, err)
+
+	hasDisplayTag := predicates["HAS_DISPLAY_TAG"]
+	filter := NewAnnotationsPredicateFilter(withPredicateRules(rules))
+	chain := newAnnotationsFilterChain(filter)
+	actualOutput := chain.doNext([]Annotation{
+		{Predicate: MENTIONS, ID: ConceptA},
+		{Predicate: hasDisplayTag, ID: ConceptA},
+		{Predicate: ISCLASSIFIEDBY, ID: ConceptA},
+		{Predicate: ISPRIMARILYCLASSIFIEDBY, ID: ConceptA},
+	})
+
+	By(byUUID).Sort(actualOutput)
+	assert.ElementsMatch(t, []Annotation{
+		{Predicate: hasDisplayTag, ID: ConceptA},
+		{Predicate: ISCLASSIFIEDBY, ID: ConceptA},
+		{Predicate: ISPRIMARILYCLASSIFIEDBY, ID: ConceptA},
+	}, actualOutput)
+}
+
+func TestLoadPredicateRules(t *testing.T) {
+	tests := map[string]struct {
+		config        string
+		expectedRules PredicateRules
+		expectedError string
+	}{
+		"YAML config": {
+			config:        "groups:\n  - [MENTIONS, ABOUT]\n  - [IS_CLASSIFIED_BY, IS_SPONSORED_BY]\n",
+			expectedRules: PredicateRules{{MENTIONS, ABOUT}, {ISCLASSIFIEDBY, "http://www.ft.com/ontology/annotation/issponsoredby"}},
+		},
+		"JSON config": {
+			config:        `{"groups": [["MENTIONS", "MAJOR_MENTIONS"]]}`,
+			expectedRules: PredicateRules{{MENTIONS, MAJORMENTIONS}},
+		},
+		"empty file": {
+			config:        "",
+			expectedError: "is empty",
+		},
+		"no groups": {
+			config:        "groups: []",
+			expectedError: "no predicate groups defined",
+		},
+		"empty group": {
+			config:        "groups:\n  - [MENTIONS]\n  - []\n",
+			expectedError: "predicate group 2 is empty",
+		},
+		"unknown relationship": {
+			config:        "groups:\n  - [MENTIONS, MENTIONED]\n",
+			expectedError: `unknown relationship "MENTIONED" in predicate group 1`,
+		},
+		"predicate listed twice": {
+			config:        "groups:\n  - [MENTIONS]\n  - [IS_CLASSIFIED_BY, HAS_BRAND]\n",
+			expectedError: "relationship HAS_BRAND in predicate group 2 has the same predicate as IS_CLASSIFIED_BY",
+		},
+		"unknown field": {
+			config:        "group:\n  - [MENTIONS]\n",
+			expectedError: "field group not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "predicate-rules.yml")
+			assert.NoError(t, os.WriteFile(path, []byte(test.config), 0o600))
+
+			rules, err := LoadPredicateRules(path)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedRules, rules)
+		})
+	}
+}
+
+func TestDefaultPredicateRulesAreValid(t *testing.T) {
+	// the default rules are the ones a predicate rules file can configure
+	rules, err := newPredicateRules([][]string{
+		{"MENTIONS", "MAJOR_MENTIONS", "ABOUT"},
+		{"IMPLICITLY_CLASSIFIED_BY", "IS_CLASSIFIED_BY", "IS_PRIMARILY_CLASSIFIED_BY"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPredicateRules, rules)
+}
+
+func TestLoadPredicateRulesMissingFile(t *testing.T) {
+	_, err := LoadPredicateRules(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.ErrorContains(t, err, "failed opening predicate rules")
+}
+
 // Tests support for sort needed by other tests in order to compare 2 arrays of annotations
 func TestSortAnnotations(t *testing.T) {
 	expected := []Annotation{
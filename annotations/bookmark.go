@@ -0,0 +1,70 @@
+package annotations
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// Neo4jBookmarkModeHeader selects what happens when Neo4j does not catch up with the Neo4j-Bookmark header in time.
+	Neo4jBookmarkModeHeader = "Neo4j-Bookmark-Mode"
+	// Neo4jBookmarkConsistencyHeader marks the responses read without waiting for the Neo4j-Bookmark header.
+	Neo4jBookmarkConsistencyHeader = "Neo4j-Bookmark-Consistency"
+)
+
+// Modes of reading with a bookmark, selected by the Neo4j-Bookmark-Mode header.
+const (
+	// bookmarkModeStrict fails the reads which timed out waiting for the bookmark
+	bookmarkModeStrict = "strict"
+	// bookmarkModeBestEffort falls back to reading without the bookmark when waiting for it timed out
+	bookmarkModeBestEffort = "best-effort"
+)
+
+// bookmarkNotGuaranteed is the value of the Neo4jBookmarkConsistencyHeader of the responses read without the bookmark.
+const bookmarkNotGuaranteed = "not-guaranteed"
+
+// invalidHeaderError reports a request header value which is not valid.
+type invalidHeaderError struct {
+	header string
+	value  string
+}
+
+func (e *invalidHeaderError) Error() string {
+	return "invalid " + e.header + " header: " + e.value
+}
+
+// parseBookmarkMode validates the value of the Neo4j-Bookmark-Mode header, which defaults to bookmarkModeStrict.
+func parseBookmarkMode(value string) (string, error) {
+	switch value {
+	case "":
+		return bookmarkModeStrict, nil
+	case bookmarkModeStrict, bookmarkModeBestEffort:
+		return value, nil
+	default:
+		return "", &invalidHeaderError{header: Neo4jBookmarkModeHeader, value: value}
+	}
+}
+
+// parseBookmarks returns the distinct bookmarks of the Neo4j-Bookmark headers, which can be repeated
+// or hold several comma-separated bookmarks.
+func parseBookmarks(values []string) []string {
+	var bookmarks []string
+	for _, v := range values {
+		bookmarks = append(bookmarks, strings.Split(v, ",")...)
+	}
+	for i := range bookmarks {
+		bookmarks[i] = strings.TrimSpace(bookmarks[i])
+	}
+	return distinctBookmarks(bookmarks)
+}
+
+// distinctBookmarks leaves out the empty and repeated bookmarks, keeping their order.
+func distinctBookmarks(bookmarks []string) []string {
+	var distinct []string
+	for _, b := range bookmarks {
+		if b != "" && !slices.Contains(distinct, b) {
+			distinct = append(distinct, b)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,129 @@
+package annotations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/gorilla/mux"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBookmarkMode(t *testing.T) {
+	mode, err := parseBookmarkMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, bookmarkModeStrict, mode)
+
+	mode, err = parseBookmarkMode("best-effort")
+	assert.NoError(t, err)
+	assert.Equal(t, bookmarkModeBestEffort, mode)
+
+	_, err = parseBookmarkMode("eventual")
+	assert.EqualError(t, err, "invalid Neo4j-Bookmark-Mode header: eventual")
+}
+
+func TestParseBookmarks(t *testing.T) {
+	assert.Nil(t, parseBookmarks(nil))
+	assert.Nil(t, parseBookmarks([]string{""}))
+	assert.Equal(t, []string{"FB:a", "FB:b", "FB:c"}, parseBookmarks([]string{"FB:a, FB:b", "FB:c", "FB:a,"}))
+}
+
+func TestGetAnnotationsBookmarkMode(t *testing.T) {
+	// the driver times out waiting for any bookmark
+	annotationsDriver := mockDriver{
+		readFunc: func(_ context.Context, _ string, bookmarks []string) (Annotations, bool, error) {
+			if len(bookmarks) > 0 {
+				return nil, false, newReadError(fmt.Errorf("failed looking up annotations: %w", &neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.BookmarkTimeout"}))
+			}
+			return Annotations{pacAnnotationA}, true, nil
+		},
+	}
+
+	tests := map[string]struct {
+		bookmark            string
+		mode                string
+		expectedStatusCode  int
+		expectedBody        string
+		expectedConsistency string
+	}{
+		"strict by default": {
+			bookmark:           "FB:kcwQnrEEnFpfSJ2PtiykK/JNh8oBozhIkA==",
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeBookmarkTimeout, knownUUID, "Timed out waiting for Neo4j to catch up with the Neo4j-Bookmark header for content with uuid 12345"),
+		},
+		"strict": {
+			bookmark:           "FB:kcwQnrEEnFpfSJ2PtiykK/JNh8oBozhIkA==",
+			mode:               "strict",
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeBookmarkTimeout, knownUUID, "Timed out waiting for Neo4j to catch up with the Neo4j-Bookmark header for content with uuid 12345"),
+		},
+		"best effort": {
+			bookmark:            "FB:kcwQnrEEnFpfSJ2PtiykK/JNh8oBozhIkA==",
+			mode:                "best-effort",
+			expectedStatusCode:  http.StatusOK,
+			expectedBody:        `[{"predicate":"http://www.ft.com/ontology/annotation/about","id":"6bbd0457-15ab-4ddc-ab82-0cd5b8d9ce18","apiUrl":"","types":null}]`,
+			expectedConsistency: bookmarkNotGuaranteed,
+		},
+		"best effort without bookmark": {
+			mode:               "best-effort",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"predicate":"http://www.ft.com/ontology/annotation/about","id":"6bbd0457-15ab-4ddc-ab82-0cd5b8d9ce18","apiUrl":"","types":null}]`,
+		},
+		"invalid mode": {
+			bookmark:           "FB:kcwQnrEEnFpfSJ2PtiykK/JNh8oBozhIkA==",
+			mode:               "eventual",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid-parameter","detail":"invalid Neo4j-Bookmark-Mode header: eventual",
+				"instance":"12345","param":"Neo4j-Bookmark-Mode","transactionId":"tid_test"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+			req := newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID))
+			if tc.bookmark != "" {
+				req.Header.Set(Neo4jBookmarkHeader, tc.bookmark)
+			}
+			if tc.mode != "" {
+				req.Header.Set(Neo4jBookmarkModeHeader, tc.mode)
+			}
+
+			rec := httptest.NewRecorder()
+			r := mux.NewRouter()
+			r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatusCode, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			assert.Equal(t, tc.expectedConsistency, rec.Header().Get(Neo4jBookmarkConsistencyHeader))
+		})
+	}
+}
+
+func TestGetAnnotationsBookmarks(t *testing.T) {
+	var readBookmarks []string
+	annotationsDriver := mockDriver{
+		readResultFunc: func(_ context.Context, _ string, bookmarks []string) (readResult, error) {
+			readBookmarks = bookmarks
+			return readResult{annotations: Annotations{pacAnnotationA}, found: true, bookmarks: []string{"FB:read-1", "FB:read-2"}}, nil
+		},
+	}
+	hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+	req := newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID))
+	req.Header.Add(Neo4jBookmarkHeader, "FB:annotations-rw")
+	req.Header.Add(Neo4jBookmarkHeader, "FB:concepts-rw-1,FB:concepts-rw-2")
+
+	rec := httptest.NewRecorder()
+	r := mux.NewRouter()
+	r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"FB:annotations-rw", "FB:concepts-rw-1", "FB:concepts-rw-2"}, readBookmarks)
+	assert.Equal(t, []string{"FB:read-1", "FB:read-2"}, rec.Header().Values(Neo4jBookmarkHeader))
+}
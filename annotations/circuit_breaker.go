@@ -0,0 +1,101 @@
+package annotations
+
+import (
+	"sync"
+	"time"
+)
+
+// States of a circuitBreaker.
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// circuitBreaker stops routing reads to a backend after a number of consecutive failures.
+// Once open for the configured duration, it lets a single probe read through: the circuit closes
+// if the probe succeeds and opens again if it fails.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	openFor   time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, openFor time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, openFor: openFor, now: time.Now}
+}
+
+// allow reports whether a read can be routed to the backend. A half-open circuit allows a single probe at a time.
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.stateLocked() {
+	case circuitClosed:
+		return true
+	case circuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return false
+	}
+}
+
+// success closes the circuit.
+func (cb *circuitBreaker) success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.probing = false
+}
+
+// failure counts a failed read, opening the circuit when the threshold is reached or the probe failed.
+func (cb *circuitBreaker) failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+	cb.countFailureLocked()
+}
+
+// checkFailure counts a failed health check of the backend like a failed read. A probe read in flight is left
+// to complete, so that its result decides whether the circuit closes.
+func (cb *circuitBreaker) checkFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.countFailureLocked()
+}
+
+func (cb *circuitBreaker) countFailureLocked() {
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.openedAt = cb.now()
+	}
+}
+
+func (cb *circuitBreaker) state() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.stateLocked()
+}
+
+func (cb *circuitBreaker) stateLocked() string {
+	switch {
+	case cb.failures < cb.threshold:
+		return circuitClosed
+	case cb.now().Sub(cb.openedAt) < cb.openFor:
+		return circuitOpen
+	default:
+		return circuitHalfOpen
+	}
+}
@@ -0,0 +1,65 @@
+package annotations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cb := newCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	cb.failure()
+	assert.Equal(t, circuitClosed, cb.state(), "a failure below the threshold")
+	assert.True(t, cb.allow())
+
+	cb.failure()
+	assert.Equal(t, circuitOpen, cb.state(), "the threshold of consecutive failures")
+	assert.False(t, cb.allow())
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, circuitHalfOpen, cb.state(), "open for the configured duration")
+	assert.True(t, cb.allow(), "the probe")
+	assert.False(t, cb.allow(), "a read during the probe")
+
+	cb.failure()
+	assert.Equal(t, circuitOpen, cb.state(), "a failed probe")
+
+	now = now.Add(time.Minute)
+	assert.True(t, cb.allow(), "the next probe")
+	cb.success()
+	assert.Equal(t, circuitClosed, cb.state(), "a successful probe")
+	assert.True(t, cb.allow())
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	cb := newCircuitBreaker(2, time.Minute)
+
+	cb.failure()
+	cb.success()
+	cb.failure()
+	assert.Equal(t, circuitClosed, cb.state(), "the failures are not consecutive")
+}
+
+func TestCircuitBreakerCheckFailureLeavesTheProbe(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cb := newCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	cb.checkFailure()
+	cb.checkFailure()
+	assert.Equal(t, circuitOpen, cb.state(), "the threshold of consecutive failed checks")
+
+	now = now.Add(time.Minute)
+	assert.True(t, cb.allow(), "the probe")
+	cb.checkFailure()
+	assert.Equal(t, circuitOpen, cb.state(), "a failed check during the probe")
+
+	now = now.Add(time.Minute)
+	assert.False(t, cb.allow(), "a read while the probe is in flight")
+	cb.success()
+	assert.Equal(t, circuitClosed, cb.state(), "a successful probe")
+}
@@ -0,0 +1,88 @@
+package annotations
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	ontology "github.com/Financial-Times/cm-graph-ontology/v2"
+)
+
+// conceptTypeParents holds the hierarchy of the ontology concept types, used to derive the labels of the concepts
+// loaded from fixtures the same way they are labelled in Neo4j, and to match the types the annotations are filtered by
+// ignoring case, whichever the backend.
+var conceptTypeParents = map[string]string{
+	"Concept":                     "Thing",
+	"Classification":              "Concept",
+	"Brand":                       "Classification",
+	"Genre":                       "Classification",
+	"Subject":                     "Classification",
+	"Section":                     "Classification",
+	"SpecialReport":               "Classification",
+	"AlphavilleSeries":            "Classification",
+	"Topic":                       "Concept",
+	"Location":                    "Concept",
+	"Person":                      "Concept",
+	"Organisation":                "Concept",
+	"Company":                     "Organisation",
+	"PublicCompany":               "Company",
+	"PrivateCompany":              "Company",
+	"FinancialInstrument":         "Concept",
+	"MembershipRole":              "Concept",
+	"BoardRole":                   "MembershipRole",
+	"Membership":                  "Concept",
+	"IndustryClassification":      "Concept",
+	"NAICSIndustryClassification": "IndustryClassification",
+}
+
+// conceptTypeLabels returns the labels of a concept type, including the labels of all its ancestor types.
+func conceptTypeLabels(conceptType string) ([]string, error) {
+	if _, ok := conceptTypeParents[conceptType]; !ok {
+		return nil, fmt.Errorf("unknown concept type %q", conceptType)
+	}
+
+	var labels []string
+	for t := conceptType; t != ""; t = conceptTypeParents[t] {
+		labels = append([]string{t}, labels...)
+	}
+	return labels, nil
+}
+
+// resolveType maps a concept type given by its short name (Person) or ontology URI to its short name.
+// The known types are the ones of the ontology, and the types of the concept type hierarchy are also matched ignoring case.
+func resolveType(value string) (string, bool) {
+	// the URIs of the types end with their short names
+	name, isURI := value, strings.Contains(value, "/")
+	if isURI {
+		name = path.Base(value)
+	}
+
+	if uri, ok := typeURI(name); ok && (!isURI || strings.EqualFold(uri, value)) {
+		return name, true
+	}
+	matches := func(conceptType string) bool {
+		if !strings.EqualFold(conceptType, name) {
+			return false
+		}
+		uri, ok := typeURI(conceptType)
+		return !isURI || ok && strings.EqualFold(uri, value)
+	}
+	if matches("Thing") {
+		return "Thing", true
+	}
+	for conceptType := range conceptTypeParents {
+		if matches(conceptType) {
+			return conceptType, true
+		}
+	}
+	return "", false
+}
+
+// typeURI returns the ontology URI of a concept type given by its short name, if the ontology knows it.
+func typeURI(conceptType string) (string, bool) {
+	uris, err := ontology.TypeURIs([]string{conceptType})
+	if err != nil || len(uris) != 1 {
+		return "", false
+	}
+	return uris[0], true
+}
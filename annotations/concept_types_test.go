@@ -0,0 +1,55 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConceptTypeLabels(t *testing.T) {
+	labels, err := conceptTypeLabels("PublicCompany")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Thing", "Concept", "Organisation", "Company", "PublicCompany"}, labels)
+}
+
+func TestResolveType(t *testing.T) {
+	tests := map[string]struct {
+		value        string
+		expectedType string
+	}{
+		"short name":                 {value: "Person", expectedType: "Person"},
+		"short name ignoring case":   {value: "organisation", expectedType: "Organisation"},
+		"root type":                  {value: "thing", expectedType: "Thing"},
+		"uri":                        {value: "http://www.ft.com/ontology/company/PublicCompany", expectedType: "PublicCompany"},
+		"uri ignoring case":          {value: "http://www.ft.com/ontology/person/person", expectedType: "Person"},
+		"unknown short name":         {value: "Persn"},
+		"unknown uri":                {value: "http://www.ft.com/ontology/Persn"},
+		"short name in another path": {value: "http://www.ft.com/ontology/Person"},
+		"empty":                      {value: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conceptType, ok := resolveType(test.value)
+			assert.Equal(t, test.expectedType != "", ok)
+			assert.Equal(t, test.expectedType, conceptType)
+		})
+	}
+}
+
+func TestResolveTypeOutsideTheHierarchy(t *testing.T) {
+	// a type of the ontology which is not part of the concept type hierarchy
+	parent := conceptTypeParents["Genre"]
+	delete(conceptTypeParents, "Genre")
+	defer func() { conceptTypeParents["Genre"] = parent }()
+
+	conceptType, ok := resolveType("Genre")
+	assert.True(t, ok)
+	assert.Equal(t, "Genre", conceptType)
+
+	uri, ok := typeURI("Genre")
+	assert.True(t, ok)
+	conceptType, ok = resolveType(uri)
+	assert.True(t, ok)
+	assert.Equal(t, "Genre", conceptType)
+}
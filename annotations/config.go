@@ -0,0 +1,31 @@
+package annotations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeConfigFile decodes a YAML or JSON configuration file into v, rejecting unknown fields.
+// what names the configuration in the errors.
+func decodeConfigFile(path string, what string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed opening %s: %w", what, err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err = dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s file %s is empty", what, path)
+		}
+		return fmt.Errorf("failed parsing %s file %s: %w", what, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package annotations
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// configResponse holds the effective reloadable settings, with the defaults in place of the settings not configured.
+type configResponse struct {
+	CacheControl        string               `json:"cacheControl"`
+	PredicateRules      PredicateRules       `json:"predicateRules"`
+	LifecyclePolicy     *LifecyclePolicy     `json:"lifecyclePolicy"`
+	PublicationRegistry *PublicationRegistry `json:"publicationRegistry"`
+}
+
+// GetConfig responds with the settings the annotations are currently served with, e.g. to verify a reload.
+func GetConfig(hctx *HandlerCtx) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		s := hctx.Settings()
+		resp := configResponse{
+			CacheControl:        s.CacheControlHeader,
+			PredicateRules:      s.predicateRules(),
+			LifecyclePolicy:     s.lifecyclePolicy(),
+			PublicationRegistry: s.publicationRegistry(),
+		}
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			hctx.Log.WithError(err).Error("failed writing config response")
+		}
+	}
+}
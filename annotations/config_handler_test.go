@@ -0,0 +1,36 @@
+package annotations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConfig(t *testing.T) {
+	registry := &PublicationRegistry{Publications: []Publication{{UUID: ftPink, ShortName: "ft", DisplayName: "Financial Times"}}, Default: "ft"}
+	hctx := NewHandlerCtx(mockDriver{}, &Settings{CacheControlHeader: "max-age=60, public", PublicationRegistry: registry},
+		logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+
+	rr := httptest.NewRecorder()
+	GetConfig(hctx)(rr, httptest.NewRequest("GET", "/__config", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
+
+	var config struct {
+		CacheControl        string              `json:"cacheControl"`
+		PredicateRules      PredicateRules      `json:"predicateRules"`
+		LifecyclePolicy     LifecyclePolicy     `json:"lifecyclePolicy"`
+		PublicationRegistry PublicationRegistry `json:"publicationRegistry"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+	assert.Equal(t, "max-age=60, public", config.CacheControl)
+	assert.Equal(t, DefaultPredicateRules, config.PredicateRules, "the default predicate rules")
+	assert.Equal(t, DefaultLifecyclePolicy, config.LifecyclePolicy, "the default lifecycle policy")
+	assert.Equal(t, *registry, config.PublicationRegistry, "the configured publication registry")
+}
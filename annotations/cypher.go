@@ -1,28 +1,90 @@
 package annotations
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
+	"time"
 
 	ontology "github.com/Financial-Times/cm-graph-ontology/v2"
 	cmneo4j "github.com/Financial-Times/cm-neo4j-driver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const IDPrefix = "http://api.ft.com/things/"
 
 type driver interface {
-	read(id string, bookmark string) (anns Annotations, found bool, err error)
+	read(ctx context.Context, id string, bookmarks []string) (readResult, error)
 	checkConnectivity() error
 }
 
+// readResult holds the annotations read for a piece of content.
+type readResult struct {
+	annotations Annotations
+	found       bool
+	// warnings describe the annotations which were dropped because they could not be mapped to the response format
+	warnings []Warning
+	// rows are the raw rows the annotations were mapped from
+	rows []neoAnnotation
+	// bookmarks represent the point the annotations were read at, so that later reads can be causally consistent with this one
+	bookmarks []string
+}
+
+// unmappedAnnotationsError is returned in strict mapping mode when some of the annotations could not be mapped.
+type unmappedAnnotationsError struct {
+	contentUUID string
+	warnings    []Warning
+}
+
+func (e *unmappedAnnotationsError) Error() string {
+	return fmt.Sprintf("%d annotations of content %s could not be mapped", len(e.warnings), e.contentUUID)
+}
+
+// neoReader runs the read queries of CypherDriver, like Neo4jDriver does.
+// ReadMultiple runs all the queries in a single read transaction and decodes the rows of each one of them,
+// failing with cmneo4j.ErrNoResultsFound only once all of them ran, if any of them returned no rows.
+type neoReader interface {
+	ReadMultiple(queries []*cmneo4j.Query, bookmarks []string) (string, error)
+	VerifyConnectivity() error
+}
+
 type CypherDriver struct {
-	driver  *cmneo4j.Driver
+	driver  neoReader
 	baseURL string
+	// strictMapping makes read fail instead of dropping the annotations which cannot be mapped
+	strictMapping bool
+	// bookmarkReadTimeout bounds the transactions of the reads with a bookmark, unless it is zero
+	bookmarkReadTimeout time.Duration
 }
 
-func NewCypherDriver(driver *cmneo4j.Driver, baseURL string) CypherDriver {
-	return CypherDriver{driver: driver, baseURL: baseURL}
+func NewCypherDriver(driver neoReader, baseURL string, opts ...func(*CypherDriver)) CypherDriver {
+	cd := CypherDriver{driver: driver, baseURL: baseURL}
+	for _, opt := range opts {
+		opt(&cd)
+	}
+
+	return cd
+}
+
+// WithStrictMapping makes the driver return an error when any of the annotations cannot be mapped to the response format.
+func WithStrictMapping(strict bool) func(*CypherDriver) {
+	return func(cd *CypherDriver) {
+		cd.strictMapping = strict
+	}
+}
+
+// WithBookmarkReadTimeout makes Neo4j terminate the transactions of the reads with a bookmark which run for longer than timeout,
+// so that they release their connections. The terminated reads fail like any other failing query.
+// It requires a driver bounding its transactions like Neo4jDriver. The transactions are not bounded if timeout is zero.
+func WithBookmarkReadTimeout(timeout time.Duration) func(*CypherDriver) {
+	return func(cd *CypherDriver) {
+		cd.bookmarkReadTimeout = timeout
+	}
 }
 
 func (cd CypherDriver) checkConnectivity() error {
@@ -58,16 +120,18 @@ type neoAnnotation struct {
 	PlatformVersion string   `json:"platformVersion,omitempty"`
 }
 
-// read method reads the annotations for a given contentUUID from Neo4j.
-// If non-empty bookmark is provided, it will be used in the session reading from Neo4j. The bookmark guarantees
-// that the instance executing the read transaction is at least up to date to the point represented by the bookmark.
-// If not existing bookmark is given but in correct format, the read will be successful.
-// If bookmark in not valid format is provided, the read will fail. The format of the bookmarks is checked by the db.
-func (cd CypherDriver) read(contentUUID string, bookmark string) (anns Annotations, found bool, err error) {
-	var results []neoAnnotation
+// annotationQuery is one of the independent traversals whose results make up the annotations of a piece of content.
+type annotationQuery struct {
+	name   string
+	cypher string
+}
 
-	query := &cmneo4j.Query{
-		Cypher: `
+// annotationQueries holds the explicit annotations query and the queries resolving the implicit annotations.
+// They are executed in a single read transaction and their results are merged.
+var annotationQueries = []annotationQuery{
+	{
+		name: "explicit",
+		cypher: `
 		MATCH (content:Content{uuid:$contentUUID})-[rel]-(:Concept)-[:EQUIVALENT_TO]->(canonicalConcept:Concept)
 		OPTIONAL MATCH (canonicalConcept)<-[:EQUIVALENT_TO]-(:Concept)<-[:ISSUED_BY]-(figi:FinancialInstrument)
 		OPTIONAL MATCH (canonicalConcept)<-[:EQUIVALENT_TO]-(:Concept)-[naicsRel:HAS_INDUSTRY_CLASSIFICATION{rank:1}]->(NAICSIndustryClassification)-[:EQUIVALENT_TO]->(naics:NAICSIndustryClassification)
@@ -85,7 +149,11 @@ func (cd CypherDriver) read(contentUUID string, bookmark string) (anns Annotatio
 			naicsRel.rank as naicsRank,
 			rel.lifecycle as lifecycle,
 			rel.publication as publication
-		UNION
+		`,
+	},
+	{
+		name: "brand-parent",
+		cypher: `
 		MATCH (content:Content{uuid:$contentUUID})-[rel]-(:Concept)-[:EQUIVALENT_TO]->(canonicalBrand:Brand)
 		OPTIONAL MATCH (canonicalBrand)<-[:EQUIVALENT_TO]-(leafBrand:Brand)-[r:HAS_PARENT*0..]->(parentBrand:Brand)-[:EQUIVALENT_TO]->(canonicalParent:Brand)
 		RETURN 
@@ -102,7 +170,11 @@ func (cd CypherDriver) read(contentUUID string, bookmark string) (anns Annotatio
 			null as naicsRank,
 			rel.lifecycle as lifecycle,
 			rel.publication as publication
-		UNION
+		`,
+	},
+	{
+		name: "implied-by",
+		cypher: `
 		MATCH (content:Content{uuid:$contentUUID})-[rel:ABOUT]-(:Concept)-[:EQUIVALENT_TO]->(canonicalConcept:Concept)
 		MATCH (canonicalConcept)<-[:EQUIVALENT_TO]-(leafConcept:Topic)<-[:IMPLIED_BY*1..]-(impliedByBrand:Brand)-[:EQUIVALENT_TO]->(canonicalBrand:Brand)
 		RETURN 
@@ -119,7 +191,11 @@ func (cd CypherDriver) read(contentUUID string, bookmark string) (anns Annotatio
 			null as naicsRank,
 			rel.lifecycle as lifecycle,
 			rel.publication as publication
-		UNION
+		`,
+	},
+	{
+		name: "broader",
+		cypher: `
 		MATCH (content:Content{uuid:$contentUUID})-[rel:ABOUT]-(:Concept)-[:EQUIVALENT_TO]->(canonicalConcept:Concept)
 		MATCH (canonicalConcept)<-[:EQUIVALENT_TO]-(leafConcept:Concept)-[:HAS_BROADER*1..]->(implicit:Concept)-[:EQUIVALENT_TO]->(canonicalImplicit)
 		WHERE NOT (canonicalImplicit)<-[:EQUIVALENT_TO]-(:Concept)<-[:ABOUT]-(content) // filter out the original abouts
@@ -137,7 +213,11 @@ func (cd CypherDriver) read(contentUUID string, bookmark string) (anns Annotatio
 			null as naicsRank,
 			rel.lifecycle as lifecycle,
 			rel.publication as publication
-		UNION
+		`,
+	},
+	{
+		name: "part-of",
+		cypher: `
 		MATCH (content:Content{uuid:$contentUUID})-[rel:ABOUT]-(:Concept)-[:EQUIVALENT_TO]->(canonicalConcept:Concept)
 		MATCH (canonicalConcept)<-[:EQUIVALENT_TO]-(leafConcept:Location)-[:IS_PART_OF*1..]->(implicit:Concept)-[:EQUIVALENT_TO]->(canonicalImplicit)
 		WHERE NOT (canonicalImplicit)<-[:EQUIVALENT_TO]-(:Concept)<-[:ABOUT]-(content) // filter out the original abouts
@@ -156,39 +236,211 @@ func (cd CypherDriver) read(contentUUID string, bookmark string) (anns Annotatio
 			rel.lifecycle as lifecycle,
 			rel.publication as publication
 		`,
-		Params: map[string]interface{}{"contentUUID": contentUUID},
-		Result: &results,
-	}
+	},
+}
 
-	bookmarks := make([]string, 0, 1)
-	if len(bookmark) > 0 {
-		bookmarks = append(bookmarks, bookmark)
-	}
+// read method reads the annotations for a given contentUUID from Neo4j.
+// The queries in annotationQueries are run in a single read transaction, and their results are merged.
+// If bookmarks are provided, they will be used in the session reading from Neo4j. The bookmarks guarantee
+// that the instance executing the read transaction is at least up to date to the points represented by all of them.
+// If not existing bookmark is given but in correct format, the read will be successful.
+// If bookmark in not valid format is provided, the read will fail. The format of the bookmarks is checked by the db.
+// The transaction of a read with bookmarks is bounded by the bookmark read timeout of the driver.
+// The result holds the bookmark of the read session.
+// Failed reads return a readError classifying the failure, e.g. as an invalid bookmark or a transient cluster error.
+// Annotations which cannot be mapped to the response format are dropped and reported as warnings,
+// or make the read fail with unmappedAnnotationsError when the driver is in strict mapping mode.
+func (cd CypherDriver) read(ctx context.Context, contentUUID string, bookmarks []string) (readResult, error) {
+	ctx, span := startSpan(ctx, "CypherDriver.read", trace.WithAttributes(
+		attribute.String(contentUUIDAttribute, contentUUID),
+		attribute.Int(bookmarksCountAttribute, len(bookmarks)),
+	))
+	defer span.End()
 
-	_, err = cd.driver.ReadMultiple([]*cmneo4j.Query{query}, bookmarks)
-	if errors.Is(err, cmneo4j.ErrNoResultsFound) {
-		return Annotations{}, false, nil
+	var timeout time.Duration
+	if len(bookmarks) > 0 {
+		timeout = cd.bookmarkReadTimeout
+	}
+	results, readBookmarks, err := cd.readAll(ctx, contentUUID, bookmarks, timeout)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "reading annotations failed")
+		return readResult{},
+			newReadError(fmt.Errorf("failed looking up annotations for contentUUID %s: %w", contentUUID, err))
 	}
+	span.SetAttributes(attribute.Int(rowsCountAttribute, len(results)))
+
+	res, err := mapResults(contentUUID, results, cd.baseURL, cd.strictMapping)
+	res.bookmarks = readBookmarks
 	if err != nil {
-		return Annotations{}, false,
-			fmt.Errorf("failed looking up annotations for contentUUID %s: %w", contentUUID, err)
+		return res, err
 	}
+	span.SetAttributes(attribute.Int(annotationsCountAttribute, len(res.annotations)))
 
-	var mappedAnnotations []Annotation
-	found = false
+	return res, nil
+}
 
+// mapResults maps the rows read for a piece of content to the response format.
+// Rows which cannot be mapped are dropped and reported as warnings, or make mapResults fail
+// with unmappedAnnotationsError in strict mapping mode. The raw rows are kept in the result even then.
+func mapResults(contentUUID string, results []neoAnnotation, baseURL string, strictMapping bool) (readResult, error) {
+	if len(results) == 0 {
+		return readResult{annotations: Annotations{}}, nil
+	}
+
+	res := readResult{rows: results}
 	for idx := range results {
-		annotation, err := mapToResponseFormat(results[idx], cd.baseURL)
-		if err == nil {
-			found = true
-			mappedAnnotations = append(mappedAnnotations, annotation)
+		annotation, err := mapToResponseFormat(results[idx], baseURL)
+		if err != nil {
+			reason := mappingFailureReason(err)
+			mappingFailures.WithLabelValues(reason).Inc()
+			res.warnings = append(res.warnings, Warning{
+				ConceptID:    results[idx].ID,
+				Relationship: results[idx].Predicate,
+				Reason:       reason,
+				Message:      err.Error(),
+			})
+			continue
+		}
+		res.found = true
+		res.annotations = append(res.annotations, annotation)
+	}
+
+	if strictMapping && len(res.warnings) > 0 {
+		return readResult{rows: results}, &unmappedAnnotationsError{contentUUID: contentUUID, warnings: res.warnings}
+	}
+
+	return res, nil
+}
+
+// annotationQueriesName labels the latency of the annotation queries, which are run together.
+const annotationQueriesName = "annotations"
+
+// readAll executes all annotationQueries in a single read transaction and merges their results,
+// so that they read the same state of the graph from the same instance, holding a single connection.
+// It returns the bookmark of the session the queries were read in, even if they found nothing.
+// The transaction is bounded by timeout, unless it is zero.
+func (cd CypherDriver) readAll(ctx context.Context, contentUUID string, bookmarks []string, timeout time.Duration) ([]neoAnnotation, []string, error) {
+	_, span := startSpan(ctx, "CypherDriver.readAll", trace.WithAttributes(attribute.String(queryNameAttribute, annotationQueriesName)))
+	defer span.End()
+
+	results := make([][]neoAnnotation, len(annotationQueries))
+	queries := make([]*cmneo4j.Query, len(annotationQueries))
+	for i, q := range annotationQueries {
+		queries[i] = &cmneo4j.Query{
+			Cypher: q.cypher,
+			Params: map[string]interface{}{"contentUUID": contentUUID},
+			Result: &results[i],
+		}
+	}
+
+	start := time.Now()
+	var bookmark string
+	var err error
+	if br, ok := cd.driver.(boundedReader); ok && timeout > 0 {
+		bookmark, err = br.ReadMultipleWithin(timeout, queries, bookmarks)
+	} else {
+		bookmark, err = cd.driver.ReadMultiple(queries, bookmarks)
+	}
+	queryDuration.WithLabelValues(annotationQueriesName).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, cmneo4j.ErrNoResultsFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "query failed")
+		return nil, nil, fmt.Errorf("annotation queries failed: %w", err)
+	}
+
+	rows := mergeResults(results)
+	span.SetAttributes(attribute.Int(rowsCountAttribute, len(rows)))
+	return rows, distinctBookmarks([]string{bookmark}), nil
+}
+
+// mergeResults concatenates the rows of the annotation queries in order, dropping the rows
+// which an earlier query already returned. Rows differing only in the order of their types
+// or publications are the same.
+func mergeResults(results [][]neoAnnotation) []neoAnnotation {
+	var merged []neoAnnotation
+	seen := make(map[string]bool)
+	for _, rows := range results {
+		for _, row := range rows {
+			key := rowKey(row)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, row)
 		}
 	}
 
-	return mappedAnnotations, found, nil
+	return merged
+}
+
+// rowKey identifies a row of the annotation queries by all of its fields.
+func rowKey(row neoAnnotation) string {
+	row.Types = sortedCopy(row.Types)
+	row.CanonicalTypes = sortedCopy(row.CanonicalTypes)
+	row.Publication = sortedCopy(row.Publication)
+	key, _ := json.Marshal(row)
+	return string(key)
+}
+
+func sortedCopy(values []string) []string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// Reasons for which an annotation read from Neo4j could not be mapped to the response format.
+const (
+	mappingFailureAPIURL    = "api_url"
+	mappingFailureIDURI     = "id_uri"
+	mappingFailureType      = "unknown_type"
+	mappingFailurePredicate = "unknown_predicate"
+)
+
+// mappingError is returned by mapToResponseFormat and carries the reason of the failure.
+type mappingError struct {
+	reason string
+	err    error
+}
+
+func (e *mappingError) Error() string {
+	return e.err.Error()
+}
+
+func (e *mappingError) Unwrap() error {
+	return e.err
+}
+
+func mappingFailureReason(err error) string {
+	var mErr *mappingError
+	if errors.As(err, &mErr) {
+		return mErr.reason
+	}
+	return "unknown"
 }
 
 func mapToResponseFormat(neoAnn neoAnnotation, baseURL string) (Annotation, error) {
+	ann, err := mapConcept(neoAnn, baseURL)
+	if err != nil {
+		return ann, err
+	}
+
+	predicate, err := getPredicateFromRelationship(neoAnn.Predicate)
+	if err != nil {
+		return ann, &mappingError{
+			reason: mappingFailurePredicate,
+			err:    fmt.Errorf("could not find predicate for ID %s for relationship %s: %w", ann.ID, neoAnn.Predicate, err),
+		}
+	}
+	ann.Predicate = predicate
+	ann.Lifecycle = neoAnn.Lifecycle
+	ann.Publication = neoAnn.Publication
+
+	return ann, nil
+}
+
+// mapConcept maps the fields of the annotated concept, leaving out the ones of the annotation relationship.
+func mapConcept(neoAnn neoAnnotation, baseURL string) (Annotation, error) {
 	var ann Annotation
 
 	ann.PrefLabel = neoAnn.PrefLabel
@@ -206,29 +458,30 @@ func mapToResponseFormat(neoAnn neoAnnotation, baseURL string) (Annotation, erro
 
 	apiURL, err := ontology.APIURL(neoAnn.ID, neoAnn.Types, baseURL)
 	if err != nil {
-		return ann, fmt.Errorf("could not construct api url for uuid %s with types %s", neoAnn.ID, neoAnn.Types)
+		return ann, &mappingError{
+			reason: mappingFailureAPIURL,
+			err:    fmt.Errorf("could not construct api url for uuid %s with types %s", neoAnn.ID, neoAnn.Types),
+		}
 	}
 	ann.APIURL = apiURL
 
 	id, err := getIDURI(neoAnn.ID)
 	if err != nil {
-		return ann, fmt.Errorf("could not construct ID uri for uuid %s", neoAnn.ID)
+		return ann, &mappingError{
+			reason: mappingFailureIDURI,
+			err:    fmt.Errorf("could not construct ID uri for uuid %s", neoAnn.ID),
+		}
 	}
 	ann.ID = id
 
 	types, err := ontology.TypeURIs(neoAnn.Types)
 	if err != nil || len(types) == 0 {
-		return ann, fmt.Errorf("could not map type URIs for uuid %s with types %s: concept not found", neoAnn.ID, neoAnn.Types)
+		return ann, &mappingError{
+			reason: mappingFailureType,
+			err:    fmt.Errorf("could not map type URIs for uuid %s with types %s: concept not found", neoAnn.ID, neoAnn.Types),
+		}
 	}
 	ann.Types = types
-
-	predicate, err := getPredicateFromRelationship(neoAnn.Predicate)
-	if err != nil {
-		return ann, fmt.Errorf("could not find predicate for ID %s for relationship %s: %w", ann.ID, ann.Predicate, err)
-	}
-	ann.Predicate = predicate
-	ann.Lifecycle = neoAnn.Lifecycle
-	ann.Publication = neoAnn.Publication
 	ann.IsDeprecated = neoAnn.IsDeprecated
 	ann.GeonamesFeatureCode = neoAnn.GeonamesFeatureCode
 
@@ -0,0 +1,225 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	cmneo4j "github.com/Financial-Times/cm-neo4j-driver"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNeoReader answers each annotation query with the rows or the error configured for its name,
+// reading all the queries in one transaction like Neo4jDriver does.
+type fakeNeoReader struct {
+	rows map[string][]neoAnnotation
+	errs map[string]error
+
+	mu        sync.Mutex
+	bookmarks [][]string
+	queries   [][]string
+}
+
+func (fr *fakeNeoReader) ReadMultiple(queries []*cmneo4j.Query, bookmarks []string) (string, error) {
+	names := make([]string, len(queries))
+	for i, q := range queries {
+		names[i] = queryName(q.Cypher)
+	}
+	fr.mu.Lock()
+	fr.bookmarks = append(fr.bookmarks, bookmarks)
+	fr.queries = append(fr.queries, names)
+	fr.mu.Unlock()
+
+	empty := false
+	for i, name := range names {
+		if err := fr.errs[name]; err != nil {
+			return "", err
+		}
+		rows := fr.rows[name]
+		if len(rows) == 0 {
+			empty = true
+			continue
+		}
+		*(queries[i].Result.(*[]neoAnnotation)) = rows
+	}
+	if empty {
+		return "bookmark", cmneo4j.ErrNoResultsFound
+	}
+	return "bookmark", nil
+}
+
+func (fr *fakeNeoReader) VerifyConnectivity() error {
+	return nil
+}
+
+// fakeBoundedReader is a fakeNeoReader bounding its transactions, which fail with err when bounded.
+type fakeBoundedReader struct {
+	fakeNeoReader
+	err error
+
+	timeouts []time.Duration
+}
+
+func (fr *fakeBoundedReader) ReadMultipleWithin(timeout time.Duration, queries []*cmneo4j.Query, bookmarks []string) (string, error) {
+	fr.mu.Lock()
+	fr.timeouts = append(fr.timeouts, timeout)
+	fr.mu.Unlock()
+
+	if fr.err != nil {
+		return "", fr.err
+	}
+	return fr.ReadMultiple(queries, bookmarks)
+}
+
+func queryName(cypher string) string {
+	for _, q := range annotationQueries {
+		if q.cypher == cypher {
+			return q.name
+		}
+	}
+	return ""
+}
+
+func TestReadAllMergesTheQueries(t *testing.T) {
+	explicit := neoAnnotation{ID: "brand", Predicate: "IS_CLASSIFIED_BY", Lifecycle: "v1", Types: []string{"Thing", "Concept", "Brand"}}
+	parent := neoAnnotation{ID: "parent", Predicate: "IMPLICITLY_CLASSIFIED_BY", Lifecycle: "v1", Types: []string{"Thing", "Concept", "Brand"}}
+	// the same parent brand reached through another brand, with its types in another order
+	sameParent := neoAnnotation{ID: "parent", Predicate: "IMPLICITLY_CLASSIFIED_BY", Lifecycle: "v1", Types: []string{"Brand", "Concept", "Thing"}}
+	otherLifecycle := neoAnnotation{ID: "parent", Predicate: "IMPLICITLY_CLASSIFIED_BY", Lifecycle: "pac", Types: []string{"Thing", "Concept", "Brand"}}
+
+	reader := &fakeNeoReader{rows: map[string][]neoAnnotation{
+		"explicit":     {explicit},
+		"brand-parent": {parent, otherLifecycle},
+		"implied-by":   {sameParent},
+	}}
+	cd := CypherDriver{driver: reader}
+
+	rows, bookmarks, err := cd.readAll(context.Background(), "content", []string{"in"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []neoAnnotation{explicit, parent, otherLifecycle}, rows)
+	assert.Equal(t, []string{"bookmark"}, bookmarks)
+
+	// all the queries are read in a single transaction
+	assert.Equal(t, [][]string{{"in"}}, reader.bookmarks)
+	assert.Equal(t, [][]string{{"explicit", "brand-parent", "implied-by", "broader", "part-of"}}, reader.queries)
+}
+
+func TestReadAllFindsNothing(t *testing.T) {
+	cd := CypherDriver{driver: &fakeNeoReader{}}
+
+	rows, bookmarks, err := cd.readAll(context.Background(), "content", nil, 0)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+	assert.Equal(t, []string{"bookmark"}, bookmarks, "the bookmark of the read is returned even if it found nothing")
+}
+
+func TestReadAllFailsWhenAnyQueryFails(t *testing.T) {
+	errBroader := errors.New("broader failed")
+	reader := &fakeNeoReader{
+		rows: map[string][]neoAnnotation{"explicit": {{ID: "concept", Predicate: "ABOUT"}}},
+		errs: map[string]error{"broader": errBroader},
+	}
+	cd := CypherDriver{driver: reader}
+
+	rows, bookmarks, err := cd.readAll(context.Background(), "content", nil, 0)
+	assert.ErrorIs(t, err, errBroader)
+	assert.ErrorContains(t, err, "annotation queries failed")
+	assert.Nil(t, rows)
+	assert.Nil(t, bookmarks)
+}
+
+func TestReadBoundsTheTransactionsWithABookmark(t *testing.T) {
+	reader := &fakeBoundedReader{fakeNeoReader: fakeNeoReader{rows: map[string][]neoAnnotation{
+		"explicit": {{ID: "concept", Predicate: "ABOUT"}},
+	}}}
+	cd := NewCypherDriver(reader, "", WithBookmarkReadTimeout(time.Second))
+
+	_, err := cd.read(context.Background(), "content", nil)
+	require.NoError(t, err)
+	assert.Empty(t, reader.timeouts, "the reads without bookmark are not bounded")
+
+	_, err = cd.read(context.Background(), "content", []string{"FB:in"})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second}, reader.timeouts)
+}
+
+func TestReadClassifiesTheFailuresOfTheBoundedTransactions(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		expectedKind string
+	}{
+		"bookmark timeout": {
+			err:          &neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.BookmarkTimeout", Msg: "the database is not up to date with the bookmark"},
+			expectedKind: readFailureBookmarkTimeout,
+		},
+		"transaction timeout": {
+			err:          &neo4j.Neo4jError{Code: "Neo.ClientError.Transaction.TransactionTimedOut", Msg: "the transaction has been terminated"},
+			expectedKind: readFailureQuery,
+		},
+		"transient failure": {
+			err:          &neo4j.Neo4jError{Code: "Neo.TransientError.General.DatabaseUnavailable"},
+			expectedKind: readFailureTransient,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cd := NewCypherDriver(&fakeBoundedReader{err: test.err}, "", WithBookmarkReadTimeout(time.Second))
+			_, err := cd.read(context.Background(), "content", []string{"FB:in"})
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.expectedKind, readFailureKind(err))
+		})
+	}
+}
+
+func TestMergeResults(t *testing.T) {
+	tests := map[string]struct {
+		results  [][]neoAnnotation
+		expected []neoAnnotation
+	}{
+		"NoRows": {
+			results: [][]neoAnnotation{nil, nil},
+		},
+		"DuplicatesAcrossQueries": {
+			results: [][]neoAnnotation{
+				{{ID: "a", Predicate: "ABOUT", Lifecycle: "v2", Types: []string{"Thing", "Concept"}, Publication: []string{"p1", "p2"}}},
+				{{ID: "a", Predicate: "ABOUT", Lifecycle: "v2", Types: []string{"Concept", "Thing"}, Publication: []string{"p2", "p1"}}, {ID: "b", Predicate: "ABOUT", Lifecycle: "v2"}},
+			},
+			expected: []neoAnnotation{
+				{ID: "a", Predicate: "ABOUT", Lifecycle: "v2", Types: []string{"Thing", "Concept"}, Publication: []string{"p1", "p2"}},
+				{ID: "b", Predicate: "ABOUT", Lifecycle: "v2"},
+			},
+		},
+		"SameAnnotationOtherPublication": {
+			results: [][]neoAnnotation{
+				{{ID: "a", Predicate: "ABOUT", Lifecycle: "pac", Publication: []string{"p1"}}},
+				{{ID: "a", Predicate: "ABOUT", Lifecycle: "pac", Publication: []string{"p2"}}},
+			},
+			expected: []neoAnnotation{
+				{ID: "a", Predicate: "ABOUT", Lifecycle: "pac", Publication: []string{"p1"}},
+				{ID: "a", Predicate: "ABOUT", Lifecycle: "pac", Publication: []string{"p2"}},
+			},
+		},
+		"SameConceptOtherPredicateOrLifecycle": {
+			results: [][]neoAnnotation{
+				{{ID: "a", Predicate: "ABOUT", Lifecycle: "v2"}, {ID: "a", Predicate: "MENTIONS", Lifecycle: "v2"}},
+				{{ID: "a", Predicate: "ABOUT", Lifecycle: "pac"}},
+			},
+			expected: []neoAnnotation{
+				{ID: "a", Predicate: "ABOUT", Lifecycle: "v2"},
+				{ID: "a", Predicate: "MENTIONS", Lifecycle: "v2"},
+				{ID: "a", Predicate: "ABOUT", Lifecycle: "pac"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, mergeResults(test.results))
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package annotations
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	annrw "github.com/Financial-Times/annotations-rw-neo4j/v4/annotations"
 	"github.com/Financial-Times/base-ft-rw-app-go/v2/baseftrwapp"
@@ -167,7 +169,9 @@ func init() {
 
 type cypherDriverTestSuite struct {
 	suite.Suite
+	// driver writes the test data and reader reads the annotations, like the service does
 	driver *cmneo4j.Driver
+	reader *Neo4jDriver
 }
 
 var allUUIDs = []string{contentUUID, contentWithNoAnnotationsUUID, contentWithParentAndChildBrandUUID,
@@ -189,11 +193,13 @@ func newCypherDriverTestSuite() *cypherDriverTestSuite {
 func (s *cypherDriverTestSuite) SetupTest() {
 	log := logger.NewUPPLogger("public-annotations-api-cm-neo4j", "PANIC")
 	s.driver = getNeo4jDriver(s.T())
+	s.reader = getNeo4jReader(s.T())
 	writeAllDataToDB(s.T(), s.driver, log)
 }
 
 func (s *cypherDriverTestSuite) TearDownTest() {
 	cleanDB(s.T(), s.driver)
+	assert.NoError(s.T(), s.reader.Close())
 }
 
 func getNeo4jDriver(t *testing.T) *cmneo4j.Driver {
@@ -213,6 +219,24 @@ func getNeo4jDriver(t *testing.T) *cmneo4j.Driver {
 	return driver
 }
 
+// getNeo4jReader creates the Neo4jDriver the annotations are read with, connected to the same instance as getNeo4jDriver.
+func getNeo4jReader(t *testing.T) *Neo4jDriver {
+	if testing.Short() {
+		t.Skip("Skipping Neo4j integration tests.")
+		return nil
+	}
+
+	l := logger.NewUPPLogger("public-annotations-api-neo4j", "PANIC")
+	url := os.Getenv("NEO4J_TEST_URL")
+	if url == "" {
+		url = "bolt://localhost:7687"
+	}
+
+	reader, err := NewNeo4jDriver(url, l)
+	require.NoError(t, err, "could not create a new neo4j driver")
+	return reader
+}
+
 func (s *cypherDriverTestSuite) TestRetrieveMultipleAnnotations() {
 	expectedAnnotations := Annotations{
 		getExpectedMentionsFakebookAnnotation(),
@@ -224,12 +248,23 @@ func (s *cypherDriverTestSuite) TestRetrieveMultipleAnnotations() {
 		expectedAnnotation(brandParentUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], v1Lifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentUUID, s.T())
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
 }
 
+func (s *cypherDriverTestSuite) TestProbe() {
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
+
+	latency, err := annotationsDriver.probe(context.Background())
+	assert.NoError(s.T(), err)
+	assert.Positive(s.T(), latency)
+
+	_, err = annotationsDriver.missingSchema(context.Background())
+	assert.NoError(s.T(), err, "the indexes and constraints should be readable")
+}
+
 func (s *cypherDriverTestSuite) TestRetrievePacAndV2AnnotationsAsPriority() {
 	expectedAnnotations := Annotations{
 		getExpectedMetalMickeyAnnotation(pacLifecycle),
@@ -241,7 +276,7 @@ func (s *cypherDriverTestSuite) TestRetrievePacAndV2AnnotationsAsPriority() {
 		expectedAnnotation(brandChildUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], pacLifecycle),
 		expectedAnnotation(brandParentUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], pacLifecycle),
 	}
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	writePacAnnotations(s.T(), s.driver, nil)
 	// assert data for filtering
 	numOfV1Annotations, _ := count(v1Lifecycle, s.driver)
@@ -271,7 +306,7 @@ func (s *cypherDriverTestSuite) TestRetrieveImplicitAbouts() {
 		getExpectedMentionsFakebookAnnotation(),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	writeAboutAnnotations(s.T(), s.driver)
 
 	anns := getAndCheckAnnotations(annotationsDriver, contentUUID, s.T())
@@ -292,7 +327,7 @@ func (s *cypherDriverTestSuite) TestRetrieveCyclicImplicitAbouts() {
 		getExpectedMallStreetJournalAnnotation(),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	writeCyclicAboutAnnotations(s.T(), s.driver)
 
 	anns := getAndCheckAnnotations(annotationsDriver, contentUUID, s.T())
@@ -312,7 +347,7 @@ func (s *cypherDriverTestSuite) TestRetrieveMultipleAnnotationsIfPacAnnotationCa
 		expectedAnnotation(brandParentUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], v1Lifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	writeBrokenPacAnnotations(s.T(), s.driver)
 	// assert data for filtering
 	numOfV1Annotations, _ := count(v1Lifecycle, s.driver)
@@ -333,7 +368,7 @@ func (s *cypherDriverTestSuite) TestRetrieveContentWithParentBrand() {
 		expectedAnnotation(brandParentUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], v1Lifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentWithParentAndChildBrandUUID, s.T())
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
@@ -346,7 +381,7 @@ func (s *cypherDriverTestSuite) TestRetrieveContentWithGrandParentBrand() {
 		expectedAnnotation(brandParentUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], v1Lifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentWithThreeLevelsOfBrandUUID, s.T())
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
@@ -358,7 +393,7 @@ func (s *cypherDriverTestSuite) TestRetrieveContentWithCircularBrand() {
 		expectedAnnotation(brandCircularBUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], v1Lifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentWithCircularBrandUUID, s.T())
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
@@ -369,7 +404,7 @@ func (s *cypherDriverTestSuite) TestRetrieveContentWithJustParentBrand() {
 		expectedAnnotation(brandParentUUID, brandType, predicates["IS_CLASSIFIED_BY"], v1Lifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentWithOnlyFTUUID, s.T())
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
@@ -383,7 +418,7 @@ func (s *cypherDriverTestSuite) TestRetrieveContentBrandsOfDifferentTypes() {
 		expectedAnnotation(brandCircularBUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], v1Lifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentWithCircularBrandUUID, s.T())
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
@@ -399,7 +434,7 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationWithHasBrand() {
 		expectedAnnotation(brandParentUUID, brandType, predicates["IMPLICITLY_CLASSIFIED_BY"], pacLifecycle),
 	}
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentWithHasBrand, s.T())
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
@@ -469,7 +504,7 @@ func (s *cypherDriverTestSuite) TestTransitivePropertyOfImpliedBy() {
 
 	writeJSONToAnnotationsService(t, annotationRW, "pac", "annotations-pac", contentID, "./testdata/testImplicitlyClassifiedBy/annotations.json", nil)
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentID, t)
 	assert.Equal(t, len(expected), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(t, anns, expected)
@@ -561,7 +596,7 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithImpliedBy() {
 
 			writeJSONToAnnotationsService(t, annotationRW, "pac", "annotations-pac", contentID, test.Annotations, nil)
 
-			annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+			annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 			anns := getAndCheckAnnotations(annotationsDriver, contentID, t)
 			assert.Equal(t, len(test.ExpectedAnnotations), len(anns), "Didn't get the same number of annotations")
 			assertListContainsAll(t, anns, test.ExpectedAnnotations)
@@ -591,17 +626,43 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithValidBookmark() {
 	}, []string{})
 	assert.NoError(s.T(), err, "Unexpected error writing a thing")
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 
-	anns, found, err := annotationsDriver.read(contentUUID, bookmark)
+	res, err := annotationsDriver.read(context.Background(), contentUUID, []string{bookmark})
+	anns, found := res.annotations, res.found
 	anns = applyDefaultFilters(anns)
 	assert.NoError(s.T(), err, "Unexpected error for content %s", contentUUID)
 	assert.True(s.T(), found, "Found no annotations for content %s", contentUUID)
+	assert.NotEmpty(s.T(), res.bookmarks, "Didn't get the bookmarks of the read")
 
 	assert.Equal(s.T(), len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
 	assertListContainsAll(s.T(), anns, expectedAnnotations)
 }
 
+func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithValidBookmarkWithinTheReadTimeout() {
+	// Write something to obtain valid bookmark, delete what's written after the test.
+	defer deleteUUIDs(s.T(), s.driver, []string{"test-uuid"})
+
+	bookmark, err := s.driver.WriteMultiple([]*cmneo4j.Query{
+		{
+			Cypher: "CREATE (n:Thing {uuid: 'test-uuid'})",
+		},
+	}, []string{})
+	assert.NoError(s.T(), err, "Unexpected error writing a thing")
+
+	// the transactions of the reads with a bookmark are bounded, which the reads without one are not
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL, WithBookmarkReadTimeout(10*time.Second))
+	withBookmark, err := annotationsDriver.read(context.Background(), contentUUID, []string{bookmark})
+	assert.NoError(s.T(), err, "Unexpected error for content %s", contentUUID)
+	assert.NotEmpty(s.T(), withBookmark.bookmarks, "Didn't get the bookmarks of the read")
+	withoutBookmark, err := annotationsDriver.read(context.Background(), contentUUID, nil)
+	assert.NoError(s.T(), err, "Unexpected error for content %s", contentUUID)
+
+	assert.True(s.T(), withBookmark.found, "Found no annotations for content %s", contentUUID)
+	assertListContainsAll(s.T(), withBookmark.annotations, withoutBookmark.annotations)
+	assert.Equal(s.T(), len(withoutBookmark.annotations), len(withBookmark.annotations), "Didn't get the same number of annotations")
+}
+
 func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithNonExistingBookmark() {
 	expectedAnnotations := Annotations{
 		getExpectedMentionsFakebookAnnotation(),
@@ -617,9 +678,10 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithNonExistingBookmark()
 	// successfully without complying to the bookmark.
 	nonExistingBookmark := "FB:kcwQnrEEnFpfSJ2PtiykK/JNh8oBozhIkA=="
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 
-	anns, found, err := annotationsDriver.read(contentUUID, nonExistingBookmark)
+	res, err := annotationsDriver.read(context.Background(), contentUUID, []string{nonExistingBookmark})
+	anns, found := res.annotations, res.found
 	anns = applyDefaultFilters(anns)
 	assert.NoError(s.T(), err, "Unexpected error for content %s", contentUUID)
 	assert.True(s.T(), found, "Found no annotations for content %s", contentUUID)
@@ -634,12 +696,14 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithInvalidBookmark() {
 	// exactly is not okay with the format of the bookmark.
 	invalidBookmark := "sm:invalid"
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 
-	anns, found, err := annotationsDriver.read(contentUUID, invalidBookmark)
+	res, err := annotationsDriver.read(context.Background(), contentUUID, []string{invalidBookmark})
+	anns, found := res.annotations, res.found
 	assert.Error(s.T(), err)
 	var neo4jError *neo4j.Neo4jError
 	assert.True(s.T(), errors.As(err, &neo4jError))
+	assert.Equal(s.T(), readFailureInvalidBookmark, readFailureKind(err))
 
 	assert.False(s.T(), found, "Found no annotations for content %s", contentUUID)
 	assert.Equal(s.T(), len(anns), 0, "Didn't get 0 annotations")
@@ -655,8 +719,11 @@ func TestRetrieveNoAnnotationsWhenThereAreNonePresentExceptBrands(t *testing.T)
 
 	defer cleanDB(t, driver)
 
-	annotationsDriver := NewCypherDriver(driver, publicAPIURL)
-	anns, found, err := annotationsDriver.read(contentWithNoAnnotationsUUID, "")
+	reader := getNeo4jReader(t)
+	defer reader.Close()
+	annotationsDriver := NewCypherDriver(reader, publicAPIURL)
+	res, err := annotationsDriver.read(context.Background(), contentWithNoAnnotationsUUID, nil)
+	anns, found := res.annotations, res.found
 	anns = applyDefaultFilters(anns)
 	assert.NoError(err, "Unexpected error for content %s", contentWithNoAnnotationsUUID)
 	assert.False(found, "Found annotations for content %s", contentWithNoAnnotationsUUID)
@@ -679,7 +746,9 @@ func TestRetrieveAnnotationWithCorrectValues(t *testing.T) {
 		getExpectedMallStreetJournalAnnotation(),
 	}
 
-	annotationsDriver := NewCypherDriver(d, publicAPIURL)
+	reader := getNeo4jReader(t)
+	defer reader.Close()
+	annotationsDriver := NewCypherDriver(reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentUUID, t)
 
 	assert.Equal(len(expectedAnnotations), len(anns), "Didn't get the same number of annotations")
@@ -706,8 +775,11 @@ func TestRetrieveNoAnnotationsWhenThereAreNoConceptsPresent(t *testing.T) {
 
 	defer cleanDB(t, driver)
 
-	annotationsDriver := NewCypherDriver(driver, publicAPIURL)
-	anns, found, err := annotationsDriver.read(contentUUID, "")
+	reader := getNeo4jReader(t)
+	defer reader.Close()
+	annotationsDriver := NewCypherDriver(reader, publicAPIURL)
+	res, err := annotationsDriver.read(context.Background(), contentUUID, nil)
+	anns, found := res.annotations, res.found
 	anns = applyDefaultFilters(anns)
 	assert.NoError(err, "Unexpected error for content %s", contentUUID)
 	assert.False(found, "Found annotations for content %s", contentUUID)
@@ -728,7 +800,9 @@ func TestRetrieveAnnotationsWithNAICSOrganisation(t *testing.T) {
 	writeV2Annotations(t, driver)
 	defer cleanDB(t, driver)
 
-	annotationsDriver := NewCypherDriver(driver, publicAPIURL)
+	reader := getNeo4jReader(t)
+	defer reader.Close()
+	annotationsDriver := NewCypherDriver(reader, publicAPIURL)
 	anns := getAndCheckAnnotations(annotationsDriver, contentWithNAICSOrgUUID, t)
 
 	expectedAnnotations := Annotations{
@@ -753,7 +827,7 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithPublicationFTPink() {
 	writePacAnnotations(s.T(), s.driver, []interface{}{ftPink})
 	writeManualAnnotations(s.T(), s.driver)
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	publicationFilter := newPublicationFilter(withPublication([]string{ftPink}, true))
 	filters := []annotationsFilter{publicationFilter}
 	anns := getAndCheckAnnotationsWithSpecificFilters(annotationsDriver, contentUUID, s.T(), filters...)
@@ -772,6 +846,7 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithPublicationFTPink() {
 	for i := range expectedAnnotations {
 		if expectedAnnotations[i].Lifecycle != v2Lifecycle {
 			expectedAnnotations[i].Publication = []string{ftPink}
+			expectedAnnotations[i].PublicationNames = []string{"Financial Times"}
 		}
 	}
 
@@ -783,7 +858,7 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithEmptyPublicationFilte
 	writePacAnnotations(s.T(), s.driver, []interface{}{ftPink})
 	writeManualAnnotations(s.T(), s.driver)
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	publicationFilter := newPublicationFilter(withPublication([]string{}, true))
 	filters := []annotationsFilter{publicationFilter}
 	anns := getAndCheckAnnotationsWithSpecificFilters(annotationsDriver, contentUUID, s.T(), filters...)
@@ -802,6 +877,7 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithEmptyPublicationFilte
 	for i := range expectedAnnotations {
 		if expectedAnnotations[i].Lifecycle != v2Lifecycle {
 			expectedAnnotations[i].Publication = []string{ftPink}
+			expectedAnnotations[i].PublicationNames = []string{"Financial Times"}
 		}
 	}
 
@@ -813,7 +889,7 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithoutPublicationAndFTPi
 	writePacAnnotations(s.T(), s.driver, nil)
 	writeManualAnnotations(s.T(), s.driver)
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	publicationFilter := newPublicationFilter(withPublication([]string{ftPink}, true))
 	filters := []annotationsFilter{publicationFilter}
 	anns := getAndCheckAnnotationsWithSpecificFilters(annotationsDriver, contentUUID, s.T(), filters...)
@@ -837,17 +913,18 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithPublicationSV() {
 	writePacAnnotations(s.T(), s.driver, []interface{}{ftPink})
 	writeManualAnnotations(s.T(), s.driver)
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	publicationFilter := newPublicationFilter(withPublication([]string{sv}, true))
 	filters := []annotationsFilter{publicationFilter}
 	anns := getAndCheckAnnotationsWithSpecificFilters(annotationsDriver, contentUUID, s.T(), filters...)
 
 	expectedAnnotations := Annotations{
-		getExpectedAboutFakebookAnnotation(lifecycleMap["manual"]),
+		getExpectedAboutFakebookAnnotation(DefaultLifecyclePolicy.Lifecycles["manual"]),
 	}
 
 	for i := range expectedAnnotations {
 		expectedAnnotations[i].Publication = []string{sv}
+		expectedAnnotations[i].PublicationNames = []string{"Sustainable Views"}
 	}
 
 	assert.Len(s.T(), anns, len(expectedAnnotations), "Didn't get the same number of annotations")
@@ -858,13 +935,13 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithPublicationSVAndRemov
 	writePacAnnotations(s.T(), s.driver, []interface{}{ftPink})
 	writeManualAnnotations(s.T(), s.driver)
 
-	annotationsDriver := NewCypherDriver(s.driver, publicAPIURL)
+	annotationsDriver := NewCypherDriver(s.reader, publicAPIURL)
 	publicationFilter := newPublicationFilter(withPublication([]string{sv}, false))
 	filters := []annotationsFilter{publicationFilter}
 	anns := getAndCheckAnnotationsWithSpecificFilters(annotationsDriver, contentUUID, s.T(), filters...)
 
 	expectedAnnotations := Annotations{
-		getExpectedAboutFakebookAnnotation(lifecycleMap["manual"]),
+		getExpectedAboutFakebookAnnotation(DefaultLifecyclePolicy.Lifecycles["manual"]),
 	}
 
 	assert.Len(s.T(), anns, len(expectedAnnotations), "Didn't get the same number of annotations")
@@ -872,7 +949,8 @@ func (s *cypherDriverTestSuite) TestRetrieveAnnotationsWithPublicationSVAndRemov
 }
 
 func getAndCheckAnnotations(driver CypherDriver, contentUUID string, t *testing.T) Annotations {
-	anns, found, err := driver.read(contentUUID, "")
+	res, err := driver.read(context.Background(), contentUUID, nil)
+	anns, found := res.annotations, res.found
 	anns = applyDefaultFilters(anns)
 	assert.NoError(t, err, "Unexpected error for content %s", contentUUID)
 	assert.True(t, found, "Found no annotations for content %s", contentUUID)
@@ -880,7 +958,8 @@ func getAndCheckAnnotations(driver CypherDriver, contentUUID string, t *testing.
 }
 
 func getAndCheckAnnotationsWithSpecificFilters(driver CypherDriver, contentUUID string, t *testing.T, filters ...annotationsFilter) Annotations {
-	anns, found, err := driver.read(contentUUID, "")
+	res, err := driver.read(context.Background(), contentUUID, nil)
+	anns, found := res.annotations, res.found
 	anns = applyDefaultAndAdditionalFilters(anns, filters...)
 	assert.NoError(t, err, "Unexpected error for content %s", contentUUID)
 	assert.True(t, found, "Found no annotations for content %s", contentUUID)
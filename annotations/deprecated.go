@@ -0,0 +1,184 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	cmneo4j "github.com/Financial-Times/cm-neo4j-driver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Modes of handling the annotations of deprecated concepts, selected by the deprecated query parameter.
+const (
+	// deprecatedInclude returns the annotations of deprecated concepts flagged with isDeprecated
+	deprecatedInclude = "include"
+	// deprecatedExclude leaves out the annotations of deprecated concepts
+	deprecatedExclude = "exclude"
+	// deprecatedReplace returns the live successors of the deprecated concepts in their place
+	deprecatedReplace = "replace"
+)
+
+// maxSuccessionDepth bounds the SUPERSEDED_BY traversals looking for the live successor of a deprecated concept.
+const maxSuccessionDepth = 10
+
+// errSuccessorsNotSupported is returned by the successorsDriver decorators whose underlying driver cannot resolve successors.
+var errSuccessorsNotSupported = errors.New("resolving the successors of deprecated concepts is not supported by the driver")
+
+// successorsDriver is implemented by the drivers able to resolve the live successors of deprecated concepts.
+type successorsDriver interface {
+	// successors returns the live successors of the deprecated concepts with the given uuids, keyed by the deprecated uuid.
+	// The successors hold the fields of the concept only. Concepts without a live successor are left out.
+	successors(ctx context.Context, conceptUUIDs []string) (map[string]Annotation, error)
+}
+
+// parseDeprecatedMode validates the value of the deprecated query parameter, which defaults to deprecatedInclude.
+func parseDeprecatedMode(value string) (string, error) {
+	switch value {
+	case "":
+		return deprecatedInclude, nil
+	case deprecatedInclude, deprecatedExclude, deprecatedReplace:
+		return value, nil
+	default:
+		return "", &invalidQueryParamError{param: "deprecated", value: value}
+	}
+}
+
+// deprecatedConceptUUIDs returns the uuids of the deprecated concepts among the annotations.
+func deprecatedConceptUUIDs(annotations []Annotation) []string {
+	var uuids []string
+	seen := make(map[string]bool)
+	for _, ann := range annotations {
+		uuid := path.Base(ann.ID)
+		if ann.IsDeprecated && !seen[uuid] {
+			seen[uuid] = true
+			uuids = append(uuids, uuid)
+		}
+	}
+	return uuids
+}
+
+// readSuccessors resolves the live successors of deprecated concepts, if the driver supports it.
+func readSuccessors(ctx context.Context, d driver, conceptUUIDs []string) (map[string]Annotation, error) {
+	sd, ok := d.(successorsDriver)
+	if !ok {
+		return nil, errSuccessorsNotSupported
+	}
+	return sd.successors(ctx, conceptUUIDs)
+}
+
+type neoSuccessor struct {
+	DeprecatedUUID      string
+	ID                  string
+	Types               []string
+	PrefLabel           string
+	LeiCode             string
+	GeonamesFeatureCode string
+}
+
+// successorsCypher follows the SUPERSEDED_BY relationships of the source concepts of each deprecated concept,
+// returning the closest canonical concept which is not deprecated itself.
+var successorsCypher = fmt.Sprintf(`
+		UNWIND $uuids as uuid
+		MATCH (deprecated:Concept{prefUUID:uuid})<-[:EQUIVALENT_TO]-(:Concept)-[succession:SUPERSEDED_BY*1..%d]->(:Concept)-[:EQUIVALENT_TO]->(successor:Concept)
+		WHERE successor.prefUUID <> uuid AND NOT coalesce(successor.isDeprecated, false)
+		WITH uuid, successor, size(succession) as distance
+		ORDER BY distance, successor.prefUUID
+		WITH uuid, head(collect(successor)) as successor
+		RETURN
+			uuid as deprecatedUUID,
+			successor.prefUUID as id,
+			labels(successor) as types,
+			successor.prefLabel as prefLabel,
+			successor.leiCode as leiCode,
+			successor.geonamesFeatureCode as geonamesFeatureCode
+		`, maxSuccessionDepth)
+
+// successors resolves the live successors of deprecated concepts from Neo4j.
+// Successors which cannot be mapped to the response format are left out, like concepts without successors.
+func (cd CypherDriver) successors(ctx context.Context, conceptUUIDs []string) (map[string]Annotation, error) {
+	var results []neoSuccessor
+
+	query := &cmneo4j.Query{
+		Cypher: successorsCypher,
+		Params: map[string]interface{}{"uuids": conceptUUIDs},
+		Result: &results,
+	}
+
+	_, span := startSpan(ctx, "CypherDriver.successors", trace.WithAttributes(attribute.Int(conceptsCountAttribute, len(conceptUUIDs))))
+	defer span.End()
+
+	_, err := cd.driver.ReadMultiple([]*cmneo4j.Query{query}, nil)
+	if errors.Is(err, cmneo4j.ErrNoResultsFound) {
+		return map[string]Annotation{}, nil
+	}
+	if err != nil {
+		return nil, newReadError(fmt.Errorf("failed resolving the successors of deprecated concepts: %w", err))
+	}
+
+	return mapSuccessors(results, cd.baseURL), nil
+}
+
+func mapSuccessors(results []neoSuccessor, baseURL string) map[string]Annotation {
+	successors := make(map[string]Annotation, len(results))
+	for _, r := range results {
+		successor, err := mapConcept(neoAnnotation{
+			ID:                  r.ID,
+			Types:               r.Types,
+			PrefLabel:           r.PrefLabel,
+			LeiCode:             r.LeiCode,
+			GeonamesFeatureCode: r.GeonamesFeatureCode,
+		}, baseURL)
+		if err != nil {
+			mappingFailures.WithLabelValues(mappingFailureReason(err)).Inc()
+			continue
+		}
+		successors[r.DeprecatedUUID] = successor
+	}
+	return successors
+}
+
+// deprecatedFilter applies the deprecated mode to the annotations of deprecated concepts.
+type deprecatedFilter struct {
+	mode string
+	// successors are keyed by the uuid of the deprecated concept they replace
+	successors map[string]Annotation
+}
+
+func newDeprecatedFilter(mode string, successors map[string]Annotation) *deprecatedFilter {
+	return &deprecatedFilter{mode: mode, successors: successors}
+}
+
+func (f *deprecatedFilter) name() string {
+	return "deprecated"
+}
+
+func (f *deprecatedFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
+	if f.mode == deprecatedInclude {
+		return chain.doNext(in)
+	}
+
+	var out []Annotation
+	for _, ann := range in {
+		if !ann.IsDeprecated {
+			out = append(out, ann)
+			continue
+		}
+		if f.mode != deprecatedReplace {
+			continue
+		}
+		// annotations of concepts without a live successor are left out, rather than returning dead concepts
+		successor, ok := f.successors[path.Base(ann.ID)]
+		if !ok {
+			continue
+		}
+		successor.Predicate = ann.Predicate
+		successor.Lifecycle = ann.Lifecycle
+		successor.Publication = ann.Publication
+		successor.Replaces = ann.ID
+		out = append(out, successor)
+	}
+	return chain.doNext(out)
+}
@@ -0,0 +1,214 @@
+package annotations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	deprecatedUUID = "d1e2f3a4-0000-4000-8000-000000000001"
+	successorUUID  = "d1e2f3a4-0000-4000-8000-000000000002"
+	orphanUUID     = "d1e2f3a4-0000-4000-8000-000000000003"
+)
+
+var (
+	deprecatedAnnotation = Annotation{
+		ID:           IDPrefix + deprecatedUUID,
+		Predicate:    ABOUT,
+		PrefLabel:    "Old Topic",
+		IsDeprecated: true,
+		Lifecycle:    pacLifecycle,
+		Publication:  []string{sv},
+	}
+	orphanAnnotation = Annotation{
+		ID:           IDPrefix + orphanUUID,
+		Predicate:    MENTIONS,
+		PrefLabel:    "Dead Topic",
+		IsDeprecated: true,
+		Lifecycle:    pacLifecycle,
+	}
+	successor = Annotation{
+		ID:        IDPrefix + successorUUID,
+		APIURL:    "http://api.ft.com/things/" + successorUUID,
+		Types:     []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/concept/Concept", "http://www.ft.com/ontology/Topic"},
+		PrefLabel: "New Topic",
+	}
+)
+
+type successorsMockDriver struct {
+	mockDriver
+	successorsFunc func(context.Context, []string) (map[string]Annotation, error)
+}
+
+func (md successorsMockDriver) successors(ctx context.Context, conceptUUIDs []string) (map[string]Annotation, error) {
+	return md.successorsFunc(ctx, conceptUUIDs)
+}
+
+func TestParseDeprecatedMode(t *testing.T) {
+	for value, expected := range map[string]string{"": deprecatedInclude, "include": deprecatedInclude, "exclude": deprecatedExclude, "replace": deprecatedReplace} {
+		mode, err := parseDeprecatedMode(value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+
+	_, err := parseDeprecatedMode("Replace")
+	assert.EqualError(t, err, "invalid deprecated value: Replace")
+}
+
+func TestDeprecatedFilter(t *testing.T) {
+	replaced := successor
+	replaced.Predicate = ABOUT
+	replaced.Lifecycle = pacLifecycle
+	replaced.Publication = []string{sv}
+	replaced.Replaces = IDPrefix + deprecatedUUID
+
+	tests := map[string]struct {
+		mode     string
+		expected []Annotation
+	}{
+		"include": {
+			mode:     deprecatedInclude,
+			expected: []Annotation{pacAnnotationB, deprecatedAnnotation, orphanAnnotation},
+		},
+		"exclude": {
+			mode:     deprecatedExclude,
+			expected: []Annotation{pacAnnotationB},
+		},
+		"replace": {
+			mode:     deprecatedReplace,
+			expected: []Annotation{pacAnnotationB, replaced},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newDeprecatedFilter(tc.mode, map[string]Annotation{deprecatedUUID: successor})
+			chain := newAnnotationsFilterChain(f)
+			assert.Equal(t, tc.expected, chain.doNext([]Annotation{pacAnnotationB, deprecatedAnnotation, orphanAnnotation}))
+		})
+	}
+}
+
+func TestDeprecatedConceptUUIDs(t *testing.T) {
+	mentioned := deprecatedAnnotation
+	mentioned.Predicate = MENTIONS
+	assert.Equal(t, []string{deprecatedUUID, orphanUUID}, deprecatedConceptUUIDs([]Annotation{pacAnnotationA, deprecatedAnnotation, mentioned, orphanAnnotation}))
+	assert.Empty(t, deprecatedConceptUUIDs([]Annotation{pacAnnotationA}))
+}
+
+func TestGetAnnotationsDeprecated(t *testing.T) {
+	read := func(context.Context, string, []string) (Annotations, bool, error) {
+		return []Annotation{deprecatedAnnotation, orphanAnnotation}, true, nil
+	}
+
+	tests := map[string]struct {
+		driver              driver
+		query               string
+		expectedStatusCode  int
+		expectedBody        string
+		expectedAnnotations Annotations
+	}{
+		"replaced with the successor": {
+			driver: successorsMockDriver{
+				mockDriver: mockDriver{readFunc: read},
+				successorsFunc: func(_ context.Context, uuids []string) (map[string]Annotation, error) {
+					assert.Equal(t, []string{deprecatedUUID, orphanUUID}, uuids)
+					return map[string]Annotation{deprecatedUUID: successor}, nil
+				},
+			},
+			query:              "deprecated=replace",
+			expectedStatusCode: http.StatusOK,
+			expectedAnnotations: Annotations{{
+				ID:        successor.ID,
+				APIURL:    successor.APIURL,
+				Types:     successor.Types,
+				PrefLabel: successor.PrefLabel,
+				Predicate: ABOUT,
+				Replaces:  deprecatedAnnotation.ID,
+			}},
+		},
+		"excluded": {
+			driver:             mockDriver{readFunc: read},
+			query:              "deprecated=exclude",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       errorBody(http.StatusNotFound, codeNoAnnotationsAfterFiltering, knownUUID, "No annotations found for content with uuid 12345 for the specified filters."),
+		},
+		"failing to resolve the successors": {
+			driver: successorsMockDriver{
+				mockDriver: mockDriver{readFunc: read},
+				successorsFunc: func(context.Context, []string) (map[string]Annotation, error) {
+					return nil, errors.New("TEST failing to READ")
+				},
+			},
+			query:              "deprecated=replace",
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeBackendUnavailable, knownUUID, "Error getting annotations for content with uuid 12345"),
+		},
+		"replacing not supported": {
+			driver:             mockDriver{readFunc: read},
+			query:              "deprecated=replace",
+			expectedStatusCode: http.StatusNotImplemented,
+			expectedBody:       errorBody(http.StatusNotImplemented, codeNotSupported, knownUUID, "Replacing deprecated concepts of content with uuid 12345 is not supported by the configured backend"),
+		},
+		"invalid mode": {
+			driver:             mockDriver{readFunc: read},
+			query:              "deprecated=drop",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       invalidParamBody("deprecated", "drop"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hctx := NewHandlerCtx(tc.driver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+
+			rec := httptest.NewRecorder()
+			r := mux.NewRouter()
+			r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+			r.ServeHTTP(rec, newRequest(fmt.Sprintf("/content/%s/annotations?%s", knownUUID, tc.query)))
+
+			assert.Equal(t, tc.expectedStatusCode, rec.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+				return
+			}
+			actualAnns := Annotations{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actualAnns))
+			assert.Equal(t, tc.expectedAnnotations, actualAnns)
+		})
+	}
+}
+
+func TestFixturesDriverSuccessors(t *testing.T) {
+	dir := t.TempDir()
+	concepts := map[string]string{
+		"deprecated.json": `{"prefUUID": "` + deprecatedUUID + `", "prefLabel": "Old Topic", "type": "Topic", "isDeprecated": true,
+			"sourceRepresentations": [{"uuid": "` + deprecatedUUID + `", "type": "Topic", "supersededByUUIDs": ["` + orphanUUID + `"]}]}`,
+		"orphan.json": `{"prefUUID": "` + orphanUUID + `", "prefLabel": "Dead Topic", "type": "Topic", "isDeprecated": true,
+			"sourceRepresentations": [{"uuid": "` + orphanUUID + `", "type": "Topic", "supersededByUUIDs": ["` + successorUUID + `"]}]}`,
+		"successor.json": `{"prefUUID": "` + successorUUID + `", "prefLabel": "New Topic", "type": "Topic",
+			"sourceRepresentations": [{"uuid": "` + successorUUID + `", "type": "Topic"}]}`,
+	}
+	for name, concept := range concepts {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(concept), 0o600))
+	}
+
+	fd, err := NewFixturesDriver(dir, "http://api.ft.com")
+	require.NoError(t, err)
+
+	successors, err := fd.successors(context.Background(), []string{deprecatedUUID, successorUUID, knownUUID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Annotation{deprecatedUUID: successor}, successors, "the deprecated successors are skipped")
+}
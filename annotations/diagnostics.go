@@ -0,0 +1,177 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ontology "github.com/Financial-Times/cm-graph-ontology/v2"
+	cmneo4j "github.com/Financial-Times/cm-neo4j-driver"
+)
+
+// Types of the data-quality problems found while resolving the annotations of a piece of content.
+const (
+	problemNoCanonicalConcept = "no-canonical-concept"
+	problemMissingPrefLabel   = "missing-pref-label"
+	problemUnmappableType     = "unmappable-type"
+	problemUnknownPredicate   = "unknown-predicate"
+	problemHierarchyCycle     = "hierarchy-cycle"
+	problemDeprecatedConcept  = "deprecated-concept"
+)
+
+// maxHierarchyDepth bounds the length of the HAS_BROADER/HAS_PARENT cycles which are detected.
+const maxHierarchyDepth = 10
+
+// diagnosticsTimeout bounds the transaction of the diagnostics query, which traverses the concept hierarchies.
+const diagnosticsTimeout = 10 * time.Second
+
+// ContentDiagnostics lists the data-quality problems found in the annotations of a piece of content.
+type ContentDiagnostics struct {
+	ContentUUID string    `json:"contentUUID"`
+	Problems    []Problem `json:"problems"`
+}
+
+// Problem describes a single data-quality problem of an annotated concept.
+type Problem struct {
+	Type         string `json:"type"`
+	ConceptUUID  string `json:"conceptUUID"`
+	Relationship string `json:"relationship,omitempty"`
+	Detail       string `json:"detail"`
+}
+
+// errDiagnosticsNotSupported is returned by the diagnosticsDriver decorators whose underlying driver cannot diagnose.
+var errDiagnosticsNotSupported = errors.New("diagnostics are not supported by the driver")
+
+// diagnosticsDriver is implemented by the drivers able to diagnose the annotations of a piece of content.
+type diagnosticsDriver interface {
+	diagnose(ctx context.Context, contentUUID string) (diagnostics ContentDiagnostics, found bool, err error)
+}
+
+type neoDiagnostic struct {
+	ConceptUUID   string
+	Relationship  string
+	CanonicalUUID string
+	PrefLabel     string
+	Types         []string
+	IsDeprecated  bool
+	CycleUUIDs    []string
+}
+
+var diagnosticsCypher = fmt.Sprintf(`
+		MATCH (content:Content{uuid:$contentUUID})
+		OPTIONAL MATCH (content)-[rel]-(concept:Concept)
+		OPTIONAL MATCH (concept)-[:EQUIVALENT_TO]->(canonical:Concept)
+		OPTIONAL MATCH (canonical)<-[:EQUIVALENT_TO]-(looped:Concept)
+		WHERE exists((looped)-[:HAS_BROADER|HAS_PARENT*1..%d]->(looped))
+		RETURN
+			concept.uuid as conceptUUID,
+			type(rel) as relationship,
+			canonical.prefUUID as canonicalUUID,
+			canonical.prefLabel as prefLabel,
+			labels(canonical) as types,
+			canonical.isDeprecated as isDeprecated,
+			collect(DISTINCT looped.uuid) as cycleUUIDs
+		`, maxHierarchyDepth)
+
+// diagnose reads the annotated concepts of a piece of content together with their canonical concepts
+// and reports the problems which make annotations go missing from the response or look wrong in it.
+// The read is bounded by diagnosticsTimeout when the driver bounds its transactions like Neo4jDriver.
+func (cd CypherDriver) diagnose(ctx context.Context, contentUUID string) (ContentDiagnostics, bool, error) {
+	var results []neoDiagnostic
+
+	query := &cmneo4j.Query{
+		Cypher: diagnosticsCypher,
+		Params: map[string]interface{}{"contentUUID": contentUUID},
+		Result: &results,
+	}
+
+	_, span := startSpan(ctx, "CypherDriver.diagnose")
+	defer span.End()
+
+	var err error
+	if br, ok := cd.driver.(boundedReader); ok {
+		_, err = br.ReadMultipleWithin(diagnosticsTimeout, []*cmneo4j.Query{query}, nil)
+	} else {
+		_, err = cd.driver.ReadMultiple([]*cmneo4j.Query{query}, nil)
+	}
+	if errors.Is(err, cmneo4j.ErrNoResultsFound) {
+		return ContentDiagnostics{}, false, nil
+	}
+	if err != nil {
+		return ContentDiagnostics{}, false, fmt.Errorf("failed diagnosing annotations for contentUUID %s: %w", contentUUID, err)
+	}
+
+	return ContentDiagnostics{ContentUUID: contentUUID, Problems: findProblems(results)}, true, nil
+}
+
+func findProblems(results []neoDiagnostic) []Problem {
+	problems := []Problem{}
+	seen := make(map[Problem]bool)
+	add := func(p Problem) {
+		if !seen[p] {
+			seen[p] = true
+			problems = append(problems, p)
+		}
+	}
+
+	for _, r := range results {
+		// the content has no annotations at all
+		if r.ConceptUUID == "" {
+			continue
+		}
+
+		if _, ok := predicates[r.Relationship]; !ok {
+			add(Problem{
+				Type:         problemUnknownPredicate,
+				ConceptUUID:  r.ConceptUUID,
+				Relationship: r.Relationship,
+				Detail:       fmt.Sprintf("relationship %s is not a known annotation predicate", r.Relationship),
+			})
+		}
+
+		if r.CanonicalUUID == "" {
+			add(Problem{
+				Type:         problemNoCanonicalConcept,
+				ConceptUUID:  r.ConceptUUID,
+				Relationship: r.Relationship,
+				Detail:       "concept has no EQUIVALENT_TO relationship to a canonical concept",
+			})
+			continue
+		}
+
+		if r.PrefLabel == "" {
+			add(Problem{
+				Type:        problemMissingPrefLabel,
+				ConceptUUID: r.CanonicalUUID,
+				Detail:      "canonical concept has no prefLabel",
+			})
+		}
+
+		if types, err := ontology.TypeURIs(r.Types); err != nil || len(types) == 0 {
+			add(Problem{
+				Type:        problemUnmappableType,
+				ConceptUUID: r.CanonicalUUID,
+				Detail:      fmt.Sprintf("labels %v cannot be mapped to ontology types", r.Types),
+			})
+		}
+
+		if r.IsDeprecated {
+			add(Problem{
+				Type:        problemDeprecatedConcept,
+				ConceptUUID: r.CanonicalUUID,
+				Detail:      "canonical concept is deprecated",
+			})
+		}
+
+		for _, looped := range r.CycleUUIDs {
+			add(Problem{
+				Type:        problemHierarchyCycle,
+				ConceptUUID: looped,
+				Detail:      fmt.Sprintf("concept of canonical concept %s is part of a HAS_BROADER/HAS_PARENT cycle", r.CanonicalUUID),
+			})
+		}
+	}
+
+	return problems
+}
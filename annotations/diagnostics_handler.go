@@ -0,0 +1,58 @@
+package annotations
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	tid "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/gorilla/mux"
+)
+
+// GetContentDiagnostics lists the data-quality problems found while resolving the annotations of a piece of content.
+// It is meant to be used by support staff instead of querying Neo4j by hand.
+// The diagnostics count towards the limit of concurrent reads, like the annotations reads.
+func GetContentDiagnostics(hctx *HandlerCtx) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uuid := mux.Vars(r)["uuid"]
+		transactionID := tid.GetTransactionIDFromRequest(r)
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		release, err := hctx.ReadLimiter.acquire(r)
+		if errors.Is(err, errReadCancelled) {
+			return
+		}
+		if err != nil {
+			writeShedRead(hctx, w, uuid, transactionID, err)
+			return
+		}
+		defer release()
+
+		var diagnostics ContentDiagnostics
+		found := false
+		err = errDiagnosticsNotSupported
+		if d, ok := hctx.AnnotationsDriver.(diagnosticsDriver); ok {
+			diagnostics, found, err = d.diagnose(r.Context(), uuid)
+		}
+		if errors.Is(err, errDiagnosticsNotSupported) {
+			writeContentError(hctx, w, http.StatusNotImplemented, codeNotSupported, uuid, transactionID, "Diagnostics for content with uuid %s are not supported by the configured backend")
+			return
+		}
+		if err != nil {
+			hctx.Log.WithError(err).WithUUID(uuid).WithTransactionID(transactionID).Error("failed diagnosing annotations for content")
+			writeContentError(hctx, w, http.StatusServiceUnavailable, codeBackendUnavailable, uuid, transactionID, "Error diagnosing annotations for content with uuid %s")
+			return
+		}
+		if !found {
+			writeContentError(hctx, w, http.StatusNotFound, codeContentNotFound, uuid, transactionID, "Content with uuid %s not found.")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(diagnostics); err != nil {
+			hctx.Log.WithError(err).WithUUID(uuid).Error("failed writing diagnostics response")
+		}
+	}
+}
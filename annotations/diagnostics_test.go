@@ -0,0 +1,181 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/gorilla/mux"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProblems(t *testing.T) {
+	tests := map[string]struct {
+		results  []neoDiagnostic
+		expected []Problem
+	}{
+		"content without annotations": {
+			results:  []neoDiagnostic{{}},
+			expected: []Problem{},
+		},
+		"healthy annotation": {
+			results: []neoDiagnostic{
+				{ConceptUUID: ConceptA, Relationship: "MENTIONS", CanonicalUUID: ConceptA, PrefLabel: "Person A", Types: []string{"Thing", "Concept", "Person"}},
+			},
+			expected: []Problem{},
+		},
+		"concept without canonical node": {
+			results: []neoDiagnostic{
+				{ConceptUUID: ConceptA, Relationship: "ABOUT"},
+			},
+			expected: []Problem{
+				{Type: problemNoCanonicalConcept, ConceptUUID: ConceptA, Relationship: "ABOUT", Detail: "concept has no EQUIVALENT_TO relationship to a canonical concept"},
+			},
+		},
+		"unknown predicate and missing prefLabel": {
+			results: []neoDiagnostic{
+				{ConceptUUID: ConceptA, Relationship: "LIKES", CanonicalUUID: ConceptB, Types: []string{"Thing", "Concept", "Person"}},
+			},
+			expected: []Problem{
+				{Type: problemUnknownPredicate, ConceptUUID: ConceptA, Relationship: "LIKES", Detail: "relationship LIKES is not a known annotation predicate"},
+				{Type: problemMissingPrefLabel, ConceptUUID: ConceptB, Detail: "canonical concept has no prefLabel"},
+			},
+		},
+		"unmappable type and deprecated concept": {
+			results: []neoDiagnostic{
+				{ConceptUUID: ConceptA, Relationship: "MENTIONS", CanonicalUUID: ConceptA, PrefLabel: "Thing A", Types: []string{"Unknown"}, IsDeprecated: true},
+			},
+			expected: []Problem{
+				{Type: problemUnmappableType, ConceptUUID: ConceptA, Detail: "labels [Unknown] cannot be mapped to ontology types"},
+				{Type: problemDeprecatedConcept, ConceptUUID: ConceptA, Detail: "canonical concept is deprecated"},
+			},
+		},
+		"cycles are reported once": {
+			results: []neoDiagnostic{
+				{ConceptUUID: ConceptA, Relationship: "ABOUT", CanonicalUUID: ConceptA, PrefLabel: "Topic A", Types: []string{"Thing", "Concept", "Topic"}, CycleUUIDs: []string{ConceptB}},
+				{ConceptUUID: ConceptA, Relationship: "ABOUT", CanonicalUUID: ConceptA, PrefLabel: "Topic A", Types: []string{"Thing", "Concept", "Topic"}, CycleUUIDs: []string{ConceptB}},
+			},
+			expected: []Problem{
+				{Type: problemHierarchyCycle, ConceptUUID: ConceptB, Detail: fmt.Sprintf("concept of canonical concept %s is part of a HAS_BROADER/HAS_PARENT cycle", ConceptA)},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, findProblems(tc.results))
+		})
+	}
+}
+
+func TestGetContentDiagnostics(t *testing.T) {
+	// a limiter without a free slot, whose reads wait for up to a second
+	busyLimiter := NewReadLimiter(1, WithReadQueue(1, time.Second))
+	release, err := busyLimiter.acquire(newRequest("/"))
+	require.NoError(t, err)
+	defer release()
+	busyLimiter.queue <- struct{}{}
+
+	tests := map[string]struct {
+		driver             driver
+		readLimiter        *ReadLimiter
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"problems found": {
+			driver: mockDiagnosticsDriver{
+				diagnoseFunc: func(context.Context, string) (ContentDiagnostics, bool, error) {
+					return ContentDiagnostics{
+						ContentUUID: knownUUID,
+						Problems:    []Problem{{Type: problemDeprecatedConcept, ConceptUUID: ConceptA, Detail: "canonical concept is deprecated"}},
+					}, true, nil
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"contentUUID":"12345","problems":[{"type":"deprecated-concept","conceptUUID":"1a2359b1-9326-4b80-9b97-2a91ccd68d23","detail":"canonical concept is deprecated"}]}`,
+		},
+		"content not found": {
+			driver: mockDiagnosticsDriver{
+				diagnoseFunc: func(context.Context, string) (ContentDiagnostics, bool, error) {
+					return ContentDiagnostics{}, false, nil
+				},
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       errorBody(http.StatusNotFound, codeContentNotFound, knownUUID, "Content with uuid 12345 not found."),
+		},
+		"read error": {
+			driver: mockDiagnosticsDriver{
+				diagnoseFunc: func(context.Context, string) (ContentDiagnostics, bool, error) {
+					return ContentDiagnostics{}, false, errors.New("TEST failing to READ")
+				},
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeBackendUnavailable, knownUUID, "Error diagnosing annotations for content with uuid 12345"),
+		},
+		"reads limited": {
+			driver: mockDiagnosticsDriver{
+				diagnoseFunc: func(context.Context, string) (ContentDiagnostics, bool, error) {
+					return ContentDiagnostics{ContentUUID: knownUUID, Problems: []Problem{}}, true, nil
+				},
+			},
+			readLimiter:        busyLimiter,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeOverloaded, knownUUID, "Too many annotations reads in flight, retry later"),
+		},
+		"unsupported driver": {
+			driver:             mockDriver{},
+			expectedStatusCode: http.StatusNotImplemented,
+			expectedBody:       errorBody(http.StatusNotImplemented, codeNotSupported, knownUUID, "Diagnostics for content with uuid 12345 are not supported by the configured backend"),
+		},
+		"unsupported recorded driver": {
+			driver:             NewRecordingDriver(mockDriver{}, "", logger.NewUPPLogger("test-service", "PANIC")),
+			expectedStatusCode: http.StatusNotImplemented,
+			expectedBody:       errorBody(http.StatusNotImplemented, codeNotSupported, knownUUID, "Diagnostics for content with uuid 12345 are not supported by the configured backend"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hctx := &HandlerCtx{
+				AnnotationsDriver: tc.driver,
+				Log:               logger.NewUPPLogger("test-public-annotations-api", "PANIC"),
+				ReadLimiter:       tc.readLimiter,
+			}
+			rec := httptest.NewRecorder()
+			r := mux.NewRouter()
+			r.HandleFunc("/__diagnostics/content/{uuid}", GetContentDiagnostics(hctx)).Methods("GET")
+			r.ServeHTTP(rec, newRequest(fmt.Sprintf("/__diagnostics/content/%s", knownUUID)))
+
+			assert.Equal(t, tc.expectedStatusCode, rec.Code, "Wrong response code")
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String(), "Wrong response body")
+		})
+	}
+}
+
+func TestDiagnoseBoundsTheTransaction(t *testing.T) {
+	reader := &fakeBoundedReader{}
+	_, found, err := NewCypherDriver(reader, "").diagnose(context.Background(), knownUUID)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, []time.Duration{diagnosticsTimeout}, reader.timeouts)
+
+	timedOut := &neo4j.Neo4jError{Code: "Neo.ClientError.Transaction.TransactionTimedOut", Msg: "the transaction has been terminated"}
+	_, _, err = NewCypherDriver(&fakeBoundedReader{err: timedOut}, "").diagnose(context.Background(), knownUUID)
+	assert.ErrorIs(t, err, timedOut)
+}
+
+type mockDiagnosticsDriver struct {
+	mockDriver
+	diagnoseFunc func(context.Context, string) (ContentDiagnostics, bool, error)
+}
+
+func (md mockDiagnosticsDriver) diagnose(ctx context.Context, contentUUID string) (ContentDiagnostics, bool, error) {
+	return md.diagnoseFunc(ctx, contentUUID)
+}
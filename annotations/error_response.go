@@ -0,0 +1,112 @@
+package annotations
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const problemContentType = "application/problem+json; charset=UTF-8"
+
+// Stable, machine-readable codes of the error responses. Clients should rely on these rather than the detail.
+const (
+	// codeContentNotFound is returned when the content has no annotations at all
+	codeContentNotFound = "content-not-found"
+	// codeNoAnnotationsAfterFiltering is returned when the content has annotations, but none of them matched the filters
+	codeNoAnnotationsAfterFiltering = "no-annotations-after-filtering"
+	// codeInvalidParameter is returned for a query parameter the annotations cannot be filtered by, or an invalid header
+	codeInvalidParameter = "invalid-parameter"
+	// codeInvalidBookmark is returned when Neo4j rejects the Neo4j-Bookmark header
+	codeInvalidBookmark = "invalid-bookmark"
+	// codeBookmarkTimeout is returned when Neo4j did not catch up with the Neo4j-Bookmark header in time
+	codeBookmarkTimeout = "bookmark-timeout"
+	// codeBackendUnavailable is returned when the annotations could not be read from the backend
+	codeBackendUnavailable = "backend-unavailable"
+	// codeQueryFailed is returned when the backend failed running the annotations queries
+	codeQueryFailed = "query-failed"
+	// codeNotSupported is returned when the configured backend does not support the request
+	codeNotSupported = "not-supported"
+	// codeUnmappableAnnotations is returned when the annotations could not be mapped in strict mapping mode
+	codeUnmappableAnnotations = "unmappable-annotations"
+	// codeUnauthorized is returned to the admin requests without a valid token
+	codeUnauthorized = "unauthorized"
+	// codeInternalError is returned for failures of the service itself
+	codeInternalError = "internal-error"
+	// codeOverloaded is returned when too many reads are in flight, so the request is shed rather than queued
+	codeOverloaded = "overloaded"
+	// codeTooManyRequests is returned when the client of the request has too many reads in flight
+	codeTooManyRequests = "too-many-requests"
+)
+
+// errorResponse is an RFC 7807 problem details body, extended with a stable code and the transaction id.
+// The type is always about:blank, so the title is the HTTP status text.
+type errorResponse struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Code          string `json:"code"`
+	Detail        string `json:"detail"`
+	Instance      string `json:"instance,omitempty"`
+	Param         string `json:"param,omitempty"`
+	TransactionID string `json:"transactionId,omitempty"`
+}
+
+func newErrorResponse(status int, code, detail string) *errorResponse {
+	return &errorResponse{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// forContent sets the instance the error occurred for to the content uuid.
+func (e *errorResponse) forContent(uuid string) *errorResponse {
+	e.Instance = uuid
+	return e
+}
+
+// forTransaction sets the transaction id of the failed request.
+func (e *errorResponse) forTransaction(transactionID string) *errorResponse {
+	e.TransactionID = transactionID
+	return e
+}
+
+// writeErrorResponse replaces any content type set so far and responds with the problem details.
+func writeErrorResponse(hctx *HandlerCtx, w http.ResponseWriter, e *errorResponse) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(e.Status)
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		hctx.Log.WithError(err).Errorf("Error while writing response: %s", e.Detail)
+	}
+}
+
+// writeInvalidQueryParam responds with 400 to a query parameter value the annotations cannot be filtered by,
+// or to an invalid request header.
+func writeInvalidQueryParam(hctx *HandlerCtx, w http.ResponseWriter, uuid, transactionID string, err error) {
+	hctx.Log.WithError(err).WithUUID(uuid).WithTransactionID(transactionID).Error("invalid query parameter")
+	e := newErrorResponse(http.StatusBadRequest, codeInvalidParameter, "invalid query parameter")
+	var paramErr *invalidQueryParamError
+	var headerErr *invalidHeaderError
+	switch {
+	case errors.As(err, &paramErr):
+		e.Detail = "invalid " + paramErr.param + " query parameter: " + paramErr.value
+		e.Param = paramErr.param
+	case errors.As(err, &headerErr):
+		e.Detail = headerErr.Error()
+		e.Param = headerErr.header
+	}
+	writeErrorResponse(hctx, w, e.forContent(uuid).forTransaction(transactionID))
+}
+
+// writeShedRead responds to a read shed by the ReadLimiter with 429 when its client has too many reads in flight,
+// and with 503 otherwise, asking the client to retry after the maximum wait of the read queue.
+func writeShedRead(hctx *HandlerCtx, w http.ResponseWriter, uuid, transactionID string, err error) {
+	w.Header().Set("Retry-After", hctx.ReadLimiter.retryAfter())
+	e := newErrorResponse(http.StatusServiceUnavailable, codeOverloaded, "Too many annotations reads in flight, retry later")
+	if errors.Is(err, errClientLimit) {
+		e = newErrorResponse(http.StatusTooManyRequests, codeTooManyRequests, "Too many annotations reads in flight for the client, retry later")
+	}
+	writeErrorResponse(hctx, w, e.forContent(uuid).forTransaction(transactionID))
+}
@@ -0,0 +1,149 @@
+package annotations
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// invalidQueryParamError reports a query parameter value the annotations cannot be filtered by.
+type invalidQueryParamError struct {
+	param string
+	value string
+}
+
+func (e *invalidQueryParamError) Error() string {
+	return fmt.Sprintf("invalid %s value: %s", e.param, e.value)
+}
+
+// resolvePredicate maps a predicate given by its relationship name (HAS_AUTHOR), short name (hasAuthor)
+// or ontology URI to the predicate URI, ignoring case.
+func resolvePredicate(value string) (string, bool) {
+	if predicate, ok := predicates[strings.ToUpper(value)]; ok {
+		return predicate, true
+	}
+	for _, predicate := range predicates {
+		if strings.EqualFold(predicate, value) || strings.EqualFold(path.Base(predicate), value) {
+			return predicate, true
+		}
+	}
+	return "", false
+}
+
+// hasType reports whether any of the type URIs matches the type given by its short name (Person)
+// or ontology URI, ignoring case. As the types of an annotation list all its ancestors,
+// a parent type like Organisation also matches the annotations of its subtypes like Company.
+func hasType(types []string, value string) bool {
+	return slices.ContainsFunc(types, func(t string) bool {
+		return strings.EqualFold(t, value) || strings.EqualFold(path.Base(t), value)
+	})
+}
+
+// exclusionFilters creates the filters for the excludePredicate, excludeType and excludePublication query parameters
+// present in params.
+func exclusionFilters(params url.Values, registry *PublicationRegistry) ([]annotationsFilter, error) {
+	var filters []annotationsFilter
+
+	if values, ok := params["excludePredicate"]; ok {
+		var excluded []string
+		for _, v := range values {
+			predicate, ok := resolvePredicate(v)
+			if !ok {
+				return nil, &invalidQueryParamError{param: "excludePredicate", value: v}
+			}
+			excluded = append(excluded, predicate)
+		}
+		filters = append(filters, &excludePredicateFilter{predicates: excluded})
+	}
+
+	if values, ok := params["excludeType"]; ok {
+		var excluded []string
+		for _, v := range values {
+			conceptType, ok := resolveType(v)
+			if !ok {
+				return nil, &invalidQueryParamError{param: "excludeType", value: v}
+			}
+			excluded = append(excluded, conceptType)
+		}
+		filters = append(filters, &excludeTypeFilter{types: excluded})
+	}
+
+	if values, ok := params["excludePublication"]; ok {
+		excluded, err := registry.resolveParams("excludePublication", values)
+		if err != nil {
+			return nil, err
+		}
+		defaultPublication, _ := registry.lookup(registry.Default)
+		filters = append(filters, &excludePublicationFilter{publications: excluded, defaultPublication: defaultPublication.UUID})
+	}
+
+	return filters, nil
+}
+
+// excludePredicateFilter drops the annotations with any of the predicates.
+type excludePredicateFilter struct {
+	predicates []string
+}
+
+func (f *excludePredicateFilter) name() string {
+	return "excludePredicate"
+}
+
+func (f *excludePredicateFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
+	return chain.doNext(dropAnnotations(in, func(ann Annotation) bool {
+		return slices.ContainsFunc(f.predicates, func(predicate string) bool {
+			return strings.EqualFold(predicate, ann.Predicate)
+		})
+	}))
+}
+
+// excludeTypeFilter drops the annotations of concepts with any of the types.
+type excludeTypeFilter struct {
+	types []string
+}
+
+func (f *excludeTypeFilter) name() string {
+	return "excludeType"
+}
+
+func (f *excludeTypeFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
+	return chain.doNext(dropAnnotations(in, func(ann Annotation) bool {
+		return slices.ContainsFunc(f.types, func(t string) bool {
+			return hasType(ann.Types, t)
+		})
+	}))
+}
+
+// excludePublicationFilter drops the annotations belonging to any of the publications.
+// The annotations without publication belong to the default publication.
+type excludePublicationFilter struct {
+	publications       []string
+	defaultPublication string
+}
+
+func (f *excludePublicationFilter) name() string {
+	return "excludePublication"
+}
+
+func (f *excludePublicationFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
+	return chain.doNext(dropAnnotations(in, func(ann Annotation) bool {
+		if ann.Publication == nil {
+			return slices.Contains(f.publications, f.defaultPublication)
+		}
+		return slices.ContainsFunc(ann.Publication, func(pub string) bool {
+			return slices.Contains(f.publications, pub)
+		})
+	}))
+}
+
+func dropAnnotations(in []Annotation, drop func(Annotation) bool) []Annotation {
+	var out []Annotation
+	for _, ann := range in {
+		if !drop(ann) {
+			out = append(out, ann)
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package annotations
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	personTypeURI       = "http://www.ft.com/ontology/person/Person"
+	organisationTypeURI = "http://www.ft.com/ontology/organisation/Organisation"
+	companyTypeURI      = "http://www.ft.com/ontology/company/Company"
+	brandTypeURI        = "http://www.ft.com/ontology/product/Brand"
+
+	authorAnnotation = Annotation{
+		ID:        "http://api.ft.com/things/a1b2c3d4-0000-4000-8000-000000000001",
+		Predicate: predicates["HAS_AUTHOR"],
+		Types:     []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/concept/Concept", personTypeURI},
+	}
+	displayTagAnnotation = Annotation{
+		ID:          "http://api.ft.com/things/a1b2c3d4-0000-4000-8000-000000000002",
+		Predicate:   predicates["HAS_DISPLAY_TAG"],
+		Types:       []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/concept/Concept", personTypeURI},
+		Publication: []string{sv},
+	}
+	companyAnnotation = Annotation{
+		ID:          "http://api.ft.com/things/a1b2c3d4-0000-4000-8000-000000000003",
+		Predicate:   predicates["MENTIONS"],
+		Types:       []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/concept/Concept", organisationTypeURI, companyTypeURI},
+		Publication: []string{ftPink},
+	}
+	brandAnnotation = Annotation{
+		ID:        "http://api.ft.com/things/a1b2c3d4-0000-4000-8000-000000000004",
+		Predicate: predicates["IS_CLASSIFIED_BY"],
+		Types:     []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/concept/Concept", "http://www.ft.com/ontology/classification/Classification", brandTypeURI},
+	}
+)
+
+func TestResolvePredicate(t *testing.T) {
+	for _, value := range []string{"hasAuthor", "HASAUTHOR", "HAS_AUTHOR", "has_author", "http://www.ft.com/ontology/annotation/hasAuthor", "http://www.ft.com/ontology/annotation/hasauthor"} {
+		predicate, ok := resolvePredicate(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, predicates["HAS_AUTHOR"], predicate, value)
+	}
+
+	_, ok := resolvePredicate("hasEditor")
+	assert.False(t, ok)
+}
+
+func TestExclusionFilters(t *testing.T) {
+	all := []Annotation{authorAnnotation, displayTagAnnotation, companyAnnotation, brandAnnotation}
+
+	tests := map[string]struct {
+		query    string
+		expected []Annotation
+	}{
+		"no exclusions": {
+			query:    "",
+			expected: all,
+		},
+		"authors and display tags": {
+			query:    "excludePredicate=hasAuthor&excludePredicate=" + url.QueryEscape(predicates["HAS_DISPLAY_TAG"]),
+			expected: []Annotation{companyAnnotation, brandAnnotation},
+		},
+		"short type name": {
+			query:    "excludeType=person",
+			expected: []Annotation{companyAnnotation, brandAnnotation},
+		},
+		"parent type": {
+			query:    "excludeType=" + url.QueryEscape(organisationTypeURI),
+			expected: []Annotation{authorAnnotation, displayTagAnnotation, brandAnnotation},
+		},
+		"publication": {
+			query:    "excludePublication=sv",
+			expected: []Annotation{authorAnnotation, companyAnnotation, brandAnnotation},
+		},
+		"default publication": {
+			query:    "excludePublication=" + ftPink,
+			expected: []Annotation{displayTagAnnotation},
+		},
+		"combined": {
+			query:    "excludePredicate=mentions&excludeType=Brand&excludePublication=sv",
+			expected: []Annotation{authorAnnotation},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			params, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+			filters, err := exclusionFilters(params, &DefaultPublicationRegistry)
+			require.NoError(t, err)
+
+			chain := newAnnotationsFilterChain(filters...)
+			assert.Equal(t, tc.expected, chain.doNext(all))
+		})
+	}
+}
+
+func TestExclusionFiltersInvalidValues(t *testing.T) {
+	tests := map[string]string{
+		"excludePredicate=hasEditor": "invalid excludePredicate value: hasEditor",
+		"excludeType=":               "invalid excludeType value: ",
+		"excludeType=Persn":          "invalid excludeType value: Persn",
+		"excludeType=" + url.QueryEscape("http://www.ft.com/ontology/Persn"): "invalid excludeType value: http://www.ft.com/ontology/Persn",
+		"excludePublication=ft&excludePublication=unknown":                   "invalid excludePublication value: unknown",
+	}
+
+	for query, expectedError := range tests {
+		t.Run(query, func(t *testing.T) {
+			params, err := url.ParseQuery(query)
+			require.NoError(t, err)
+
+			_, err = exclusionFilters(params, &DefaultPublicationRegistry)
+			var paramErr *invalidQueryParamError
+			assert.ErrorAs(t, err, &paramErr)
+			assert.EqualError(t, err, expectedError)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+	"github.com/Financial-Times/go-logger/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Results of routing a read to a backend, as counted by the backendReads metric.
+const (
+	// backendReadServed is a read the backend answered, successfully or not
+	backendReadServed = "served"
+	// backendReadFailedOver is a read which failed on the backend and was routed to the next one
+	backendReadFailedOver = "failed_over"
+	// backendReadFailed is a read which failed on the backend with no other backend left to route it to
+	backendReadFailed = "failed"
+)
+
+const (
+	defaultCircuitFailures = 5
+	defaultCircuitOpenFor  = 30 * time.Second
+)
+
+// errNoBackendAvailable is returned by FailoverDriver when the circuits of all its backends are open.
+var errNoBackendAvailable = errors.New("the circuits of all the neo4j backends are open")
+
+// errPinnedBackendUnavailable is returned by FailoverDriver for a read with bookmarks when the circuit of the first backend is open.
+var errPinnedBackendUnavailable = errors.New("the circuit of the neo4j backend of the bookmarks is open")
+
+// failoverBackend is a cluster the reads can be routed to, guarded by its own circuit breaker.
+type failoverBackend struct {
+	name    string
+	driver  driver
+	breaker *circuitBreaker
+}
+
+// FailoverDriver routes the reads to the first of its backends, in order of preference, whose circuit is not open.
+// The reads failing with connectivity or transient errors are retried on the next backend, and open the circuit
+// of the backend after a number of consecutive failures.
+//
+// The reads with bookmarks are only routed to the first backend, as the bookmarks of a cluster are not known to the others,
+// and fail fast while its circuit is open.
+type FailoverDriver struct {
+	backends        []*failoverBackend
+	circuitFailures int
+	circuitOpenFor  time.Duration
+	log             *logger.UPPLogger
+}
+
+func NewFailoverDriver(log *logger.UPPLogger, opts ...func(*FailoverDriver)) *FailoverDriver {
+	fd := &FailoverDriver{circuitFailures: defaultCircuitFailures, circuitOpenFor: defaultCircuitOpenFor, log: log}
+	for _, opt := range opts {
+		opt(fd)
+	}
+	for _, b := range fd.backends {
+		b.breaker = newCircuitBreaker(fd.circuitFailures, fd.circuitOpenFor)
+		backendCircuitOpen.WithLabelValues(b.name).Set(0)
+	}
+	return fd
+}
+
+// WithFailoverBackend adds a backend, preferred over the backends added after it.
+func WithFailoverBackend(name string, d driver) func(*FailoverDriver) {
+	return func(fd *FailoverDriver) {
+		fd.backends = append(fd.backends, &failoverBackend{name: name, driver: d})
+	}
+}
+
+// WithCircuitBreaker opens the circuit of a backend after the given number of consecutive failures,
+// for the given duration.
+func WithCircuitBreaker(failures int, openFor time.Duration) func(*FailoverDriver) {
+	return func(fd *FailoverDriver) {
+		fd.circuitFailures = failures
+		fd.circuitOpenFor = openFor
+	}
+}
+
+// checkConnectivity succeeds if any of the backends is reachable.
+// It does not count towards opening the circuits, which the health checks of each backend do.
+func (fd *FailoverDriver) checkConnectivity() error {
+	var errs []error
+	for _, b := range fd.backends {
+		if err := b.driver.checkConnectivity(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", b.name, err))
+			continue
+		}
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// checkBackend checks the connectivity to a backend for its health check. A failed check counts towards opening its circuit,
+// but a successful one does not close it: a reachable backend may still fail the reads, so only a read closes the circuit.
+func (fd *FailoverDriver) checkBackend(b *failoverBackend) error {
+	err := b.driver.checkConnectivity()
+	if err != nil {
+		b.breaker.checkFailure()
+		err = fmt.Errorf("%s: %w", b.name, err)
+	}
+	backendCircuitOpen.WithLabelValues(b.name).Set(circuitOpenValue(b.breaker))
+	return err
+}
+
+func (fd *FailoverDriver) read(ctx context.Context, contentUUID string, bookmarks []string) (readResult, error) {
+	ctx, span := startSpan(ctx, "FailoverDriver.read")
+	defer span.End()
+
+	var res readResult
+	backend, err := fd.route(ctx, contentUUID, len(bookmarks) > 0, func(d driver) error {
+		var err error
+		res, err = d.read(ctx, contentUUID, bookmarks)
+		return err
+	})
+	span.SetAttributes(attribute.String(backendAttribute, backend))
+	return res, err
+}
+
+// successors resolves the successors on the first available backend, if it supports resolving successors.
+func (fd *FailoverDriver) successors(ctx context.Context, conceptUUIDs []string) (map[string]Annotation, error) {
+	var successors map[string]Annotation
+	_, err := fd.route(ctx, "", false, func(d driver) error {
+		var err error
+		successors, err = readSuccessors(ctx, d, conceptUUIDs)
+		return err
+	})
+	return successors, err
+}
+
+// diagnose diagnoses the annotations on the first available backend, if it supports diagnostics.
+func (fd *FailoverDriver) diagnose(ctx context.Context, contentUUID string) (ContentDiagnostics, bool, error) {
+	var diagnostics ContentDiagnostics
+	var found bool
+	_, err := fd.route(ctx, contentUUID, false, func(d driver) error {
+		dd, ok := d.(diagnosticsDriver)
+		if !ok {
+			return errDiagnosticsNotSupported
+		}
+		var err error
+		diagnostics, found, err = dd.diagnose(ctx, contentUUID)
+		return err
+	})
+	return diagnostics, found, err
+}
+
+// probe probes the first available backend, if it can be probed.
+func (fd *FailoverDriver) probe(ctx context.Context) (time.Duration, error) {
+	var latency time.Duration
+	_, err := fd.route(ctx, "", false, func(d driver) error {
+		pd, ok := d.(probeDriver)
+		if !ok {
+			return errProbeNotSupported
+		}
+		var err error
+		latency, err = pd.probe(ctx)
+		return err
+	})
+	return latency, err
+}
+
+// missingSchema checks the schema of the first available backend, if it can be probed.
+func (fd *FailoverDriver) missingSchema(ctx context.Context) ([]string, error) {
+	var missing []string
+	_, err := fd.route(ctx, "", false, func(d driver) error {
+		pd, ok := d.(probeDriver)
+		if !ok {
+			return errProbeNotSupported
+		}
+		var err error
+		missing, err = pd.missingSchema(ctx)
+		return err
+	})
+	return missing, err
+}
+
+// route calls read with the backends in order of preference until one of them answers, and returns its name.
+// A pinned read is only routed to the first backend, and fails with a transient error while its circuit is open.
+func (fd *FailoverDriver) route(ctx context.Context, contentUUID string, pinned bool, read func(d driver) error) (string, error) {
+	var lastErr error
+	for i, b := range fd.backends {
+		if !b.breaker.allow() {
+			if pinned {
+				readFailures.WithLabelValues(readFailureTransient).Inc()
+				return b.name, &readError{kind: readFailureTransient, err: fmt.Errorf("%s: %w", b.name, errPinnedBackendUnavailable)}
+			}
+			continue
+		}
+
+		err := read(b.driver)
+		if err == nil || !isFailoverError(err) {
+			// the backend answered, so a failure is not the backend being unavailable
+			b.breaker.success()
+			backendCircuitOpen.WithLabelValues(b.name).Set(0)
+			backendReads.WithLabelValues(b.name, backendReadServed).Inc()
+			return b.name, err
+		}
+
+		b.breaker.failure()
+		backendCircuitOpen.WithLabelValues(b.name).Set(circuitOpenValue(b.breaker))
+		lastErr = err
+		if pinned || i == len(fd.backends)-1 {
+			backendReads.WithLabelValues(b.name, backendReadFailed).Inc()
+			return b.name, err
+		}
+		backendReads.WithLabelValues(b.name, backendReadFailedOver).Inc()
+		fd.log.WithError(err).WithUUID(contentUUID).WithField("backend", b.name).Warn("neo4j backend unavailable, failing over to the next one")
+	}
+
+	if lastErr != nil {
+		return "", lastErr
+	}
+	readFailures.WithLabelValues(readFailureTransient).Inc()
+	return "", &readError{kind: readFailureTransient, err: errNoBackendAvailable}
+}
+
+// isFailoverError reports whether a read failed because the backend was unavailable, so it may succeed on another one.
+func isFailoverError(err error) bool {
+	return readFailureKind(err) == readFailureTransient || classifyNeo4jError(err) == readFailureTransient
+}
+
+func circuitOpenValue(cb *circuitBreaker) float64 {
+	if cb.state() == circuitClosed {
+		return 0
+	}
+	return 1
+}
+
+// healthChecks returns a check of the connectivity to each backend, reporting the state of its circuit.
+// A backend being unavailable degrades the service rather than stopping it, which the neo4j-cluster-health check reports.
+func (fd *FailoverDriver) healthChecks() []fthealth.Check {
+	checks := make([]fthealth.Check, 0, len(fd.backends))
+	for _, b := range fd.backends {
+		checks = append(checks, fthealth.Check{
+			ID:               "neo4j-backend-health-" + b.name,
+			BusinessImpact:   "Public Annotations api requests are served by the other Neo4j backends, or fail if none is available",
+			Name:             "Check connectivity to the Neo4j backend " + b.name,
+			PanicGuide:       runbookURL,
+			Severity:         2,
+			TechnicalSummary: `Cannot connect to the Neo4j backend. Reads fail over to the next backend listed in neoUrl while its circuit is open.`,
+			Checker: func() (string, error) {
+				if err := fd.checkBackend(b); err != nil {
+					return fmt.Sprintf("Error connecting to neo4j backend %s, circuit %s", b.name, b.breaker.state()), err
+				}
+				return fmt.Sprintf("Connectivity to neo4j backend %s is ok, circuit %s", b.name, b.breaker.state()), nil
+			},
+		})
+	}
+	return checks
+}
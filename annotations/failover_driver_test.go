@@ -0,0 +1,213 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var errBackendDown = newReadError(fmt.Errorf("read timed out: %w", context.DeadlineExceeded))
+
+// countingDriver reads the annotations of a single content, or fails with err, counting its reads.
+// Its connectivity checks fail with err too, unless it is reachable.
+type countingDriver struct {
+	err       error
+	reachable bool
+	reads     int
+}
+
+func (cd *countingDriver) read(_ context.Context, contentUUID string, _ []string) (readResult, error) {
+	cd.reads++
+	if cd.err != nil {
+		return readResult{}, cd.err
+	}
+	return readResult{annotations: Annotations{{ID: contentUUID}}, found: true}, nil
+}
+
+func (cd *countingDriver) checkConnectivity() error {
+	if cd.reachable {
+		return nil
+	}
+	return cd.err
+}
+
+func newTestFailoverDriver(primary, secondary driver) *FailoverDriver {
+	return NewFailoverDriver(logger.NewUPPLogger("test-public-annotations-api", "PANIC"),
+		WithCircuitBreaker(2, time.Minute),
+		WithFailoverBackend("primary", primary),
+		WithFailoverBackend("secondary", secondary))
+}
+
+func TestFailoverDriverRead(t *testing.T) {
+	queryErr := newReadError(errors.New("query failed"))
+	queryErr.kind = readFailureQuery
+
+	tests := map[string]struct {
+		primaryErr     error
+		secondaryErr   error
+		bookmarks      []string
+		expectedErr    error
+		primaryReads   int
+		secondaryReads int
+	}{
+		"PreferredBackend": {
+			primaryReads: 1,
+		},
+		"FailoverOnTransientError": {
+			primaryErr:     errBackendDown,
+			primaryReads:   1,
+			secondaryReads: 1,
+		},
+		"NoFailoverOnQueryError": {
+			primaryErr:   queryErr,
+			expectedErr:  queryErr,
+			primaryReads: 1,
+		},
+		"NoFailoverWithBookmarks": {
+			primaryErr:   errBackendDown,
+			bookmarks:    []string{"FB:bookmark"},
+			expectedErr:  errBackendDown,
+			primaryReads: 1,
+		},
+		"AllBackendsFailing": {
+			primaryErr:     errBackendDown,
+			secondaryErr:   errBackendDown,
+			expectedErr:    errBackendDown,
+			primaryReads:   1,
+			secondaryReads: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			primary := &countingDriver{err: test.primaryErr}
+			secondary := &countingDriver{err: test.secondaryErr}
+			fd := newTestFailoverDriver(primary, secondary)
+
+			res, err := fd.read(context.Background(), "uuid", test.bookmarks)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, res.found)
+			}
+			assert.Equal(t, test.primaryReads, primary.reads, "primary reads")
+			assert.Equal(t, test.secondaryReads, secondary.reads, "secondary reads")
+		})
+	}
+}
+
+func TestFailoverDriverOpenCircuit(t *testing.T) {
+	primary := &countingDriver{err: errBackendDown}
+	secondary := &countingDriver{}
+	fd := newTestFailoverDriver(primary, secondary)
+
+	for i := 0; i < 3; i++ {
+		_, err := fd.read(context.Background(), "uuid", nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, primary.reads, "the reads skip the primary once its circuit is open")
+	assert.Equal(t, 3, secondary.reads)
+	assert.Equal(t, circuitOpen, fd.backends[0].breaker.state())
+
+	// a read with bookmarks fails fast rather than being routed to a backend which does not know the bookmarks
+	_, err := fd.read(context.Background(), "uuid", []string{"FB:bookmark"})
+	assert.ErrorIs(t, err, errPinnedBackendUnavailable)
+	assert.ErrorContains(t, err, "primary")
+	assert.Equal(t, readFailureTransient, readFailureKind(err))
+	assert.Equal(t, 2, primary.reads)
+	assert.Equal(t, 3, secondary.reads)
+}
+
+func TestFailoverDriverConnectivityChecksDoNotCloseTheCircuit(t *testing.T) {
+	primary := &countingDriver{err: errBackendDown, reachable: true}
+	fd := newTestFailoverDriver(primary, &countingDriver{})
+	now := time.Now()
+	fd.backends[0].breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, _ = fd.read(context.Background(), "uuid", nil)
+	}
+	assert.Equal(t, circuitOpen, fd.backends[0].breaker.state())
+
+	// the primary is reachable but fails the reads
+	assert.NoError(t, fd.checkBackend(fd.backends[0]))
+	assert.Equal(t, circuitOpen, fd.backends[0].breaker.state())
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, fd.checkBackend(fd.backends[0]))
+	assert.Equal(t, circuitHalfOpen, fd.backends[0].breaker.state())
+
+	// only a successful probe read closes the circuit
+	primary.err = nil
+	_, err := fd.read(context.Background(), "uuid", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, primary.reads)
+	assert.Equal(t, circuitClosed, fd.backends[0].breaker.state())
+}
+
+func TestFailoverDriverConnectivityChecksOpenTheCircuit(t *testing.T) {
+	fd := newTestFailoverDriver(&countingDriver{err: errBackendDown}, &countingDriver{})
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, fd.checkBackend(fd.backends[0]), errBackendDown)
+	}
+	assert.Equal(t, circuitOpen, fd.backends[0].breaker.state())
+}
+
+func TestFailoverDriverAllCircuitsOpen(t *testing.T) {
+	fd := newTestFailoverDriver(&countingDriver{err: errBackendDown}, &countingDriver{err: errBackendDown})
+	for i := 0; i < 2; i++ {
+		_, _ = fd.read(context.Background(), "uuid", nil)
+	}
+
+	_, err := fd.read(context.Background(), "uuid", nil)
+	assert.ErrorIs(t, err, errNoBackendAvailable)
+	assert.Equal(t, readFailureTransient, readFailureKind(err))
+}
+
+func TestFailoverDriverCheckConnectivity(t *testing.T) {
+	fd := newTestFailoverDriver(&countingDriver{err: errBackendDown}, &countingDriver{})
+	assert.NoError(t, fd.checkConnectivity(), "a backend is reachable")
+
+	fd = newTestFailoverDriver(&countingDriver{err: errBackendDown}, &countingDriver{err: errBackendDown})
+	for i := 0; i < 2; i++ {
+		err := fd.checkConnectivity()
+		assert.ErrorIs(t, err, errBackendDown)
+		assert.ErrorContains(t, err, "primary")
+		assert.ErrorContains(t, err, "secondary")
+	}
+	assert.Equal(t, circuitClosed, fd.backends[0].breaker.state(), "the connectivity check does not count towards opening the circuits")
+	assert.Equal(t, circuitClosed, fd.backends[1].breaker.state(), "the connectivity check does not count towards opening the circuits")
+}
+
+func TestFailoverHealthChecks(t *testing.T) {
+	fd := newTestFailoverDriver(&countingDriver{err: errBackendDown}, &countingDriver{})
+	hctx := NewHandlerCtx(NewRecordingDriver(fd, t.TempDir(), logger.NewUPPLogger("test-public-annotations-api", "PANIC")), &Settings{}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+	hs := NewHealthScheduler()
+
+	checks, _ := ScheduleHealthChecks(hs, hctx, HealthConfig{ConnectivityInterval: time.Minute, QueryInterval: time.Minute})
+	var ids []string
+	for _, c := range checks {
+		ids = append(ids, c.ID)
+	}
+	assert.Equal(t, []string{"neo4j-cluster-health", "neo4j-query-latency", "neo4j-schema", "neo4j-backend-health-primary", "neo4j-backend-health-secondary"}, ids)
+
+	for _, sc := range hs.checks {
+		hs.run(sc)
+	}
+	assert.Equal(t, 1, fd.backends[0].breaker.failures, "a failed backend is counted once per run of the checks")
+	_, err := checks[3].Checker()
+	assert.ErrorIs(t, err, errBackendDown)
+	msg, err := checks[4].Checker()
+	assert.NoError(t, err)
+	assert.Equal(t, "Connectivity to neo4j backend secondary is ok, circuit closed (checked 0s ago)", msg)
+
+	checks, _ = ScheduleHealthChecks(NewHealthScheduler(), NewHandlerCtx(mockDriver{}, &Settings{}, logger.NewUPPLogger("test-public-annotations-api", "PANIC")), HealthConfig{})
+	assert.Len(t, checks, 1)
+}
@@ -0,0 +1,568 @@
+package annotations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fixtureConcept is a concept in the format written by concepts-rw-neo4j.
+type fixtureConcept struct {
+	PrefUUID              string          `json:"prefUUID"`
+	PrefLabel             string          `json:"prefLabel"`
+	Type                  string          `json:"type"`
+	IsDeprecated          bool            `json:"isDeprecated"`
+	LeiCode               string          `json:"leiCode"`
+	GeonamesFeatureCode   string          `json:"geonamesFeatureCode"`
+	IndustryIdentifier    string          `json:"industryIdentifier"`
+	SourceRepresentations []fixtureSource `json:"sourceRepresentations"`
+
+	labels []string
+}
+
+// fixtureSource is a source representation of a concept, equivalent to its canonical concept.
+type fixtureSource struct {
+	UUID                         string             `json:"uuid"`
+	Type                         string             `json:"type"`
+	FIGICode                     string             `json:"figiCode"`
+	IssuedBy                     string             `json:"issuedBy"`
+	BroaderUUIDs                 []string           `json:"broaderUUIDs"`
+	ParentUUIDs                  []string           `json:"parentUUIDs"`
+	ImpliedByUUIDs               []string           `json:"impliedByUUIDs"`
+	PartOfUUIDs                  []string           `json:"partOfUUIDs"`
+	SupersededByUUIDs            []string           `json:"supersededByUUIDs"`
+	NAICSIndustryClassifications []fixtureNAICSRank `json:"naicsIndustryClassifications"`
+
+	labels    []string
+	canonical string
+}
+
+type fixtureNAICSRank struct {
+	UUID string `json:"uuid"`
+	Rank int    `json:"rank"`
+}
+
+// fixtureAnnotationSet holds the annotations of a piece of content written by a single lifecycle,
+// in the format accepted by annotations-rw-neo4j.
+type fixtureAnnotationSet struct {
+	ContentUUID string   `json:"contentUUID"`
+	Lifecycle   string   `json:"lifecycle"`
+	Publication []string `json:"publication"`
+	Annotations []struct {
+		ID        string `json:"id"`
+		Predicate string `json:"predicate"`
+	} `json:"annotations"`
+}
+
+// annotationsFileName matches the names of the files holding a bare array of annotations,
+// Annotations-<contentUUID>-<lifecycle>.json, like the ones in the testdata of the integration tests.
+var annotationsFileName = regexp.MustCompile(`^Annotations-([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})-([a-z0-9]+)\.json$`)
+
+// fixtureRelationship is an annotation relationship between a piece of content and a concept.
+type fixtureRelationship struct {
+	relationship string
+	conceptUUID  string
+	lifecycle    string
+	publication  []string
+}
+
+// FixturesDriver reads the annotations from content, concepts and annotations loaded from a directory of JSON files.
+// It resolves the explicit and the implicit annotations with the same semantics as the annotationQueries,
+// which makes it possible to run the service without Neo4j.
+type FixturesDriver struct {
+	baseURL       string
+	strictMapping bool
+	log           *logger.UPPLogger
+
+	content    map[string]bool
+	concepts   map[string]*fixtureConcept
+	sources    map[string]*fixtureSource
+	impliedBy  map[string][]string
+	issuers    map[string][]string
+	lifecycles map[string][]string
+	rels       map[string]map[string][]fixtureRelationship
+}
+
+// NewFixturesDriver loads every JSON file found in dir and its subdirectories. A file is loaded as
+//   - a concept, if it has a prefUUID, in the format written by concepts-rw-neo4j;
+//   - an annotation set, if it has a contentUUID, a lifecycle and annotations in the format accepted by annotations-rw-neo4j;
+//   - a piece of content, if it has an uuid;
+//   - the annotations of a piece of content written by a lifecycle, if it holds a bare array of annotations
+//     and is named Annotations-<contentUUID>-<lifecycle>.json, e.g. Annotations-<uuid>-pac.json for annotations-pac.
+//
+// The other bare arrays of annotations are skipped, since they do not say which content they belong to,
+// and logged if a logger is configured.
+// Like in annotations-rw-neo4j, a later annotation set replaces the annotations of the same content and lifecycle.
+func NewFixturesDriver(dir string, baseURL string, opts ...func(*FixturesDriver)) (*FixturesDriver, error) {
+	fd := &FixturesDriver{
+		baseURL:    baseURL,
+		content:    make(map[string]bool),
+		concepts:   make(map[string]*fixtureConcept),
+		sources:    make(map[string]*fixtureSource),
+		impliedBy:  make(map[string][]string),
+		issuers:    make(map[string][]string),
+		lifecycles: make(map[string][]string),
+		rels:       make(map[string]map[string][]fixtureRelationship),
+	}
+	for _, opt := range opts {
+		opt(fd)
+	}
+
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+		if err := fd.loadFile(p); err != nil {
+			return fmt.Errorf("failed loading fixture %s: %w", p, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fd.link()
+	return fd, nil
+}
+
+// WithFixturesLogger logs the fixture files which are skipped.
+func WithFixturesLogger(log *logger.UPPLogger) func(*FixturesDriver) {
+	return func(fd *FixturesDriver) {
+		fd.log = log
+	}
+}
+
+// WithFixturesStrictMapping makes the fixtures driver return an error when any of the annotations cannot be mapped to the response format.
+func WithFixturesStrictMapping(strict bool) func(*FixturesDriver) {
+	return func(fd *FixturesDriver) {
+		fd.strictMapping = strict
+	}
+}
+
+func (fd *FixturesDriver) loadFile(p string) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		return fd.loadAnnotationsArray(p, data)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	switch {
+	case fields["prefUUID"] != nil:
+		var c fixtureConcept
+		if err = json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		return fd.addConcept(&c)
+	case fields["contentUUID"] != nil:
+		var set fixtureAnnotationSet
+		if err = json.Unmarshal(data, &set); err != nil {
+			return err
+		}
+		return fd.addAnnotationSet(set)
+	case fields["uuid"] != nil:
+		var c struct {
+			UUID string `json:"uuid"`
+		}
+		if err = json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		fd.content[c.UUID] = true
+		return nil
+	default:
+		return errors.New("not a concept, content or annotation set")
+	}
+}
+
+// loadAnnotationsArray loads a bare array of annotations, taking the content and the lifecycle from the file name.
+func (fd *FixturesDriver) loadAnnotationsArray(p string, data []byte) error {
+	match := annotationsFileName.FindStringSubmatch(filepath.Base(p))
+	if match == nil {
+		if fd.log != nil {
+			fd.log.WithField("file", p).Warn("skipping fixture: bare arrays of annotations have to be named Annotations-<contentUUID>-<lifecycle>.json")
+		}
+		return nil
+	}
+
+	set := fixtureAnnotationSet{ContentUUID: match[1], Lifecycle: "annotations-" + match[2]}
+	if err := json.Unmarshal(data, &set.Annotations); err != nil {
+		return err
+	}
+	return fd.addAnnotationSet(set)
+}
+
+func (fd *FixturesDriver) addConcept(c *fixtureConcept) error {
+	labels, err := conceptTypeLabels(c.Type)
+	if err != nil {
+		return err
+	}
+	c.labels = labels
+
+	for i := range c.SourceRepresentations {
+		src := &c.SourceRepresentations[i]
+		if src.labels, err = conceptTypeLabels(src.Type); err != nil {
+			return err
+		}
+		src.canonical = c.PrefUUID
+		fd.sources[src.UUID] = src
+	}
+	fd.concepts[c.PrefUUID] = c
+
+	return nil
+}
+
+func (fd *FixturesDriver) addAnnotationSet(set fixtureAnnotationSet) error {
+	if set.Lifecycle == "" {
+		return fmt.Errorf("annotations of content %s have no lifecycle", set.ContentUUID)
+	}
+
+	rels := make([]fixtureRelationship, 0, len(set.Annotations))
+	for _, ann := range set.Annotations {
+		rels = append(rels, fixtureRelationship{
+			relationship: relationshipName(ann.Predicate),
+			conceptUUID:  path.Base(ann.ID),
+			lifecycle:    set.Lifecycle,
+			publication:  set.Publication,
+		})
+	}
+
+	if fd.rels[set.ContentUUID] == nil {
+		fd.rels[set.ContentUUID] = make(map[string][]fixtureRelationship)
+	}
+	if _, ok := fd.rels[set.ContentUUID][set.Lifecycle]; !ok {
+		fd.lifecycles[set.ContentUUID] = append(fd.lifecycles[set.ContentUUID], set.Lifecycle)
+	}
+	fd.rels[set.ContentUUID][set.Lifecycle] = rels
+
+	return nil
+}
+
+// link indexes the relationships which are traversed against their direction.
+func (fd *FixturesDriver) link() {
+	for _, c := range fd.concepts {
+		for _, src := range c.SourceRepresentations {
+			for _, implied := range src.ImpliedByUUIDs {
+				fd.impliedBy[implied] = append(fd.impliedBy[implied], src.UUID)
+			}
+			if src.IssuedBy != "" && slices.Contains(src.labels, "FinancialInstrument") {
+				fd.issuers[src.IssuedBy] = append(fd.issuers[src.IssuedBy], src.FIGICode)
+			}
+		}
+	}
+	for _, uuids := range fd.impliedBy {
+		slices.Sort(uuids)
+	}
+	for _, figis := range fd.issuers {
+		slices.Sort(figis)
+	}
+}
+
+func (fd *FixturesDriver) checkConnectivity() error {
+	return nil
+}
+
+// read resolves the annotations of a piece of content from the loaded fixtures.
+// The bookmarks are ignored, as the fixtures do not change once loaded.
+func (fd *FixturesDriver) read(ctx context.Context, contentUUID string, _ []string) (readResult, error) {
+	_, span := startSpan(ctx, "FixturesDriver.read", trace.WithAttributes(attribute.String(contentUUIDAttribute, contentUUID)))
+	defer span.End()
+
+	var rels []fixtureRelationship
+	if fd.content[contentUUID] {
+		for _, lifecycle := range fd.lifecycles[contentUUID] {
+			rels = append(rels, fd.rels[contentUUID][lifecycle]...)
+		}
+	}
+
+	results := mergeResults([][]neoAnnotation{
+		fd.explicit(rels),
+		fd.brandParents(rels),
+		fd.impliedByBrands(rels),
+		fd.implicitAbouts(rels, func(src *fixtureSource) []string { return src.BroaderUUIDs }, "Concept"),
+		fd.implicitAbouts(rels, func(src *fixtureSource) []string { return src.PartOfUUIDs }, "Location"),
+	})
+	span.SetAttributes(attribute.Int(rowsCountAttribute, len(results)))
+
+	return mapResults(contentUUID, results, fd.baseURL, fd.strictMapping)
+}
+
+// successors resolves the live successors of deprecated concepts following the supersededByUUIDs of their sources,
+// like the successors query.
+func (fd *FixturesDriver) successors(ctx context.Context, conceptUUIDs []string) (map[string]Annotation, error) {
+	_, span := startSpan(ctx, "FixturesDriver.successors", trace.WithAttributes(attribute.Int(conceptsCountAttribute, len(conceptUUIDs))))
+	defer span.End()
+
+	var results []neoSuccessor
+	for _, uuid := range conceptUUIDs {
+		deprecated, ok := fd.concepts[uuid]
+		if !ok {
+			continue
+		}
+		var found *fixtureConcept
+		for _, src := range deprecated.SourceRepresentations {
+			for _, reached := range fd.traverse(src.UUID, func(src *fixtureSource) []string { return src.SupersededByUUIDs }, false) {
+				successor := fd.canonical(reached)
+				if successor != nil && successor.PrefUUID != uuid && !successor.IsDeprecated {
+					found = successor
+					break
+				}
+			}
+			if found != nil {
+				break
+			}
+		}
+		if found == nil {
+			continue
+		}
+		results = append(results, neoSuccessor{
+			DeprecatedUUID:      uuid,
+			ID:                  found.PrefUUID,
+			Types:               found.labels,
+			PrefLabel:           found.PrefLabel,
+			LeiCode:             found.LeiCode,
+			GeonamesFeatureCode: found.GeonamesFeatureCode,
+		})
+	}
+
+	return mapSuccessors(results, fd.baseURL), nil
+}
+
+// explicit resolves the explicitly annotated concepts, like the "explicit" annotation query.
+func (fd *FixturesDriver) explicit(rels []fixtureRelationship) []neoAnnotation {
+	var rows []neoAnnotation
+	for _, rel := range rels {
+		concept := fd.canonical(rel.conceptUUID)
+		if concept == nil {
+			continue
+		}
+
+		var figis []string
+		var naics []fixtureNAICS
+		for _, src := range concept.SourceRepresentations {
+			figis = append(figis, fd.issuers[src.UUID]...)
+			naics = append(naics, fd.naics(src)...)
+		}
+		// the FIGI and NAICS are optional, like in the OPTIONAL MATCH clauses of the query
+		if len(figis) == 0 {
+			figis = []string{""}
+		}
+		if len(naics) == 0 {
+			naics = []fixtureNAICS{{}}
+		}
+
+		for _, figi := range figis {
+			for _, n := range naics {
+				rows = append(rows, neoAnnotation{
+					ID:                  concept.PrefUUID,
+					IsDeprecated:        concept.IsDeprecated,
+					Predicate:           rel.relationship,
+					Types:               concept.labels,
+					PrefLabel:           concept.PrefLabel,
+					GeonamesFeatureCode: concept.GeonamesFeatureCode,
+					LeiCode:             concept.LeiCode,
+					FIGI:                figi,
+					NAICSIdentifier:     n.identifier,
+					NAICSPrefLabel:      n.prefLabel,
+					NAICSRank:           n.rank,
+					Lifecycle:           rel.lifecycle,
+					Publication:         rel.publication,
+				})
+			}
+		}
+	}
+	return rows
+}
+
+type fixtureNAICS struct {
+	identifier string
+	prefLabel  string
+	rank       int
+}
+
+// naics returns the primary industry classifications of a source concept.
+func (fd *FixturesDriver) naics(src fixtureSource) []fixtureNAICS {
+	var classifications []fixtureNAICS
+	for _, ref := range src.NAICSIndustryClassifications {
+		if ref.Rank != 1 {
+			continue
+		}
+		naics := fd.canonical(ref.UUID)
+		if naics == nil || !slices.Contains(naics.labels, "NAICSIndustryClassification") {
+			continue
+		}
+		classifications = append(classifications, fixtureNAICS{
+			identifier: naics.IndustryIdentifier,
+			prefLabel:  naics.PrefLabel,
+			rank:       ref.Rank,
+		})
+	}
+	return classifications
+}
+
+// brandParents resolves the parents of the annotated brands, like the "brand-parent" annotation query.
+func (fd *FixturesDriver) brandParents(rels []fixtureRelationship) []neoAnnotation {
+	var rows []neoAnnotation
+	for _, rel := range rels {
+		brand := fd.canonical(rel.conceptUUID)
+		if brand == nil || !slices.Contains(brand.labels, "Brand") {
+			continue
+		}
+		for _, leaf := range brand.SourceRepresentations {
+			if !slices.Contains(leaf.labels, "Brand") {
+				continue
+			}
+			parents := fd.traverse(leaf.UUID, func(src *fixtureSource) []string { return src.ParentUUIDs }, true)
+			for _, parent := range parents {
+				rows = fd.appendImplicit(rows, parent, "Brand", "IMPLICITLY_CLASSIFIED_BY", rel, false)
+			}
+		}
+	}
+	return rows
+}
+
+// impliedByBrands resolves the brands implied by the concepts the content is about, like the "implied-by" annotation query.
+func (fd *FixturesDriver) impliedByBrands(rels []fixtureRelationship) []neoAnnotation {
+	var rows []neoAnnotation
+	for _, rel := range rels {
+		concept := fd.canonical(rel.conceptUUID)
+		if rel.relationship != "ABOUT" || concept == nil {
+			continue
+		}
+		for _, leaf := range concept.SourceRepresentations {
+			if !slices.Contains(leaf.labels, "Topic") {
+				continue
+			}
+			brands := fd.traverse(leaf.UUID, func(src *fixtureSource) []string { return fd.impliedBy[src.UUID] }, false)
+			for _, brand := range brands {
+				rows = fd.appendImplicit(rows, brand, "Brand", "IMPLICITLY_CLASSIFIED_BY", rel, false)
+			}
+		}
+	}
+	return rows
+}
+
+// implicitAbouts resolves the concepts reachable through the given hierarchy from the leaf concepts with leafLabel
+// the content is about, like the "broader" and "part-of" annotation queries.
+func (fd *FixturesDriver) implicitAbouts(rels []fixtureRelationship, next func(*fixtureSource) []string, leafLabel string) []neoAnnotation {
+	abouts := make(map[string]bool)
+	for _, rel := range rels {
+		if concept := fd.canonical(rel.conceptUUID); rel.relationship == "ABOUT" && concept != nil {
+			abouts[concept.PrefUUID] = true
+		}
+	}
+
+	var rows []neoAnnotation
+	for _, rel := range rels {
+		concept := fd.canonical(rel.conceptUUID)
+		if rel.relationship != "ABOUT" || concept == nil {
+			continue
+		}
+		for _, leaf := range concept.SourceRepresentations {
+			if !slices.Contains(leaf.labels, leafLabel) {
+				continue
+			}
+			for _, implicit := range fd.traverse(leaf.UUID, next, false) {
+				if abouts[fd.sources[implicit].canonical] {
+					continue
+				}
+				rows = fd.appendImplicit(rows, implicit, "Concept", "IMPLICITLY_ABOUT", rel, true)
+			}
+		}
+	}
+	return rows
+}
+
+// appendImplicit appends the row of an implicit annotation to the canonical concept of the source concept uuid,
+// provided both the source and the canonical concept have the given label.
+func (fd *FixturesDriver) appendImplicit(rows []neoAnnotation, uuid, label, relationship string, rel fixtureRelationship, withGeonames bool) []neoAnnotation {
+	src := fd.sources[uuid]
+	concept := fd.canonical(uuid)
+	if !slices.Contains(src.labels, label) || concept == nil || !slices.Contains(concept.labels, label) {
+		return rows
+	}
+
+	row := neoAnnotation{
+		ID:           concept.PrefUUID,
+		IsDeprecated: concept.IsDeprecated,
+		Predicate:    relationship,
+		Types:        concept.labels,
+		PrefLabel:    concept.PrefLabel,
+		Lifecycle:    rel.lifecycle,
+		Publication:  rel.publication,
+	}
+	if withGeonames {
+		row.GeonamesFeatureCode = concept.GeonamesFeatureCode
+	}
+	return append(rows, row)
+}
+
+// traverse returns the uuids of the source concepts reachable from uuid following the relationships returned by next,
+// in breadth-first order. The start concept is included if includeStart is set or if it can be reached through a cycle.
+func (fd *FixturesDriver) traverse(uuid string, next func(*fixtureSource) []string, includeStart bool) []string {
+	var reached []string
+	visited := make(map[string]bool)
+	if includeStart {
+		visited[uuid] = true
+		reached = append(reached, uuid)
+	}
+
+	for queue := next(fd.sources[uuid]); len(queue) > 0; queue = queue[1:] {
+		current := queue[0]
+		src, ok := fd.sources[current]
+		if !ok || visited[current] {
+			continue
+		}
+		visited[current] = true
+		reached = append(reached, current)
+		queue = append(queue, next(src)...)
+	}
+	return reached
+}
+
+// canonical returns the canonical concept of the source concept with the given uuid, or nil if there is none.
+func (fd *FixturesDriver) canonical(uuid string) *fixtureConcept {
+	src, ok := fd.sources[uuid]
+	if !ok {
+		return nil
+	}
+	return fd.concepts[src.canonical]
+}
+
+// relationshipName converts an annotation predicate, such as isClassifiedBy, to the name of its relationship in Neo4j.
+func relationshipName(predicate string) string {
+	predicate = path.Base(predicate)
+	if strings.ToUpper(predicate) == predicate {
+		return predicate
+	}
+
+	var b strings.Builder
+	for i, r := range predicate {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package annotations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixturesDriverReadImplicitAnnotations(t *testing.T) {
+	const fixturesDir = "./testdata/testImplicitlyClassifiedBy"
+	dir := t.TempDir()
+	entries, err := os.ReadDir(fixturesDir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(fixturesDir, e.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, e.Name()), data, 0o600))
+	}
+
+	contentUUID := "96b13d2f-609b-4d6f-be33-3ccb91e1c8e9"
+	var anns []json.RawMessage
+	readFixture(t, filepath.Join(fixturesDir, "annotations.json"), &anns)
+	set, err := json.Marshal(map[string]interface{}{
+		"contentUUID": contentUUID,
+		"lifecycle":   "annotations-pac",
+		"annotations": anns,
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "annotations-pac.json"), set, 0o600))
+
+	expected := map[string]string{}
+	for fixture, relationship := range map[string]string{
+		"topic2-about.json":            "ABOUT",
+		"topic1-mentions.json":         "MENTIONS",
+		"organisation1-about.json":     "ABOUT",
+		"brand1-isClassifiedBy.json":   "IS_CLASSIFIED_BY",
+		"topic3-broader-topic2.json":   "IMPLICITLY_ABOUT",
+		"brand2-impliedBy-topic2.json": "IMPLICITLY_CLASSIFIED_BY",
+		"brand5-parent-brand1.json":    "IMPLICITLY_CLASSIFIED_BY",
+		"topic5-broader-topic3.json":   "IMPLICITLY_ABOUT",
+	} {
+		var c fixtureConcept
+		readFixture(t, filepath.Join(fixturesDir, fixture), &c)
+		expected[IDPrefix+c.PrefUUID] = predicates[relationship]
+	}
+
+	fd, err := NewFixturesDriver(dir, "http://api.ft.com")
+	require.NoError(t, err)
+
+	res, err := fd.read(context.Background(), contentUUID, nil)
+	require.NoError(t, err)
+	assert.True(t, res.found)
+	assert.Empty(t, res.warnings)
+
+	chain := newAnnotationsFilterChain(newLifecycleFilter(), NewAnnotationsPredicateFilter())
+	actual := map[string]string{}
+	for _, ann := range chain.doNext(res.annotations) {
+		actual[ann.ID] = ann.Predicate
+		assert.Equal(t, "annotations-pac", ann.Lifecycle)
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestFixturesDriverReadExplicitAnnotation(t *testing.T) {
+	dir := t.TempDir()
+	for _, fixture := range []string{
+		"Content-3fc9fe3e-af8c-4f7f-961a-e5065392bb31.json",
+		"Organisation-Fakebook-eac853f5-3859-4c08-8540-55e043719400.json",
+		"Organisation-NYT-0d9fbdfc-7d95-332b-b77b-1e69274b1b83.json",
+		"FinancialInstrument-77f613ad-1470-422c-bf7c-1dd4c3fd1693.json",
+		"NAICSIndustryClassification-38ee195d-ebdd-48a9-af4b-c8a322e7b04d.json",
+	} {
+		data, err := os.ReadFile(filepath.Join("./testdata", fixture))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fixture), data, 0o600))
+	}
+	set := `{
+		"contentUUID": "3fc9fe3e-af8c-4f7f-961a-e5065392bb31",
+		"lifecycle": "annotations-v2",
+		"publication": ["` + sv + `"],
+		"annotations": [
+			{"id": "http://api.ft.com/things/eac853f5-3859-4c08-8540-55e043719400", "predicate": "mentions"},
+			{"id": "http://api.ft.com/things/0d9fbdfc-7d95-332b-b77b-1e69274b1b83", "predicate": "about"}
+		]
+	}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "annotations-v2.json"), []byte(set), 0o600))
+
+	fd, err := NewFixturesDriver(dir, "http://api.ft.com")
+	require.NoError(t, err)
+
+	res, err := fd.read(context.Background(), "3fc9fe3e-af8c-4f7f-961a-e5065392bb31", nil)
+	require.NoError(t, err)
+	require.Len(t, res.annotations, 2)
+
+	var fakebook fixtureConcept
+	readFixture(t, "./testdata/Organisation-Fakebook-eac853f5-3859-4c08-8540-55e043719400.json", &fakebook)
+	ann := res.annotations[0]
+	assert.Equal(t, IDPrefix+fakebook.PrefUUID, ann.ID)
+	assert.Equal(t, predicates["MENTIONS"], ann.Predicate)
+	assert.Equal(t, fakebook.PrefLabel, ann.PrefLabel)
+	assert.Equal(t, fakebook.LeiCode, ann.LeiCode)
+	assert.Equal(t, "BB8000C3P0-R2D2", ann.FIGI)
+	assert.Empty(t, ann.NAICS)
+	assert.Equal(t, "annotations-v2", ann.Lifecycle)
+	assert.Equal(t, []string{sv}, ann.Publication)
+
+	ann = res.annotations[1]
+	assert.Equal(t, IDPrefix+"0d9fbdfc-7d95-332b-b77b-1e69274b1b83", ann.ID)
+	assert.Equal(t, predicates["ABOUT"], ann.Predicate)
+	assert.Empty(t, ann.FIGI)
+	assert.Equal(t, []IndustryClassification{{Identifier: "5111-test", PrefLabel: "Newspaper, Periodical, Book, and Directory Publishers", Rank: 1}}, ann.NAICS)
+}
+
+func TestFixturesDriverLoadsTheTestdata(t *testing.T) {
+	log := logger.NewUPPLogger("test-service", "WARN")
+	var logs bytes.Buffer
+	log.Out = &logs
+
+	fd, err := NewFixturesDriver("./testdata", "http://api.ft.com", WithFixturesLogger(log))
+	require.NoError(t, err)
+
+	res, err := fd.read(context.Background(), "3fc9fe3e-af8c-4f7f-961a-e5065392bb31", nil)
+	require.NoError(t, err)
+	assert.True(t, res.found)
+	assert.Empty(t, res.warnings)
+	lifecycles := map[string]bool{}
+	for _, ann := range res.annotations {
+		lifecycles[ann.Lifecycle] = true
+	}
+	assert.Equal(t, map[string]bool{"annotations-v1": true, "annotations-v2": true, "annotations-pac": true, "annotations-manual": true}, lifecycles)
+
+	res, err = fd.read(context.Background(), "3fc9fe3e-af8c-7a7a-961a-e5065392bb31", nil)
+	require.NoError(t, err)
+	require.Len(t, res.annotations, 2)
+	assert.Equal(t, "annotations-v2", res.annotations[0].Lifecycle)
+
+	// the bare arrays which do not name their content and lifecycle are skipped and logged
+	for _, skipped := range []string{
+		"Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-broken-pac.json",
+		"Annotations-7e22c8b8-b280-4e52-aa22-fa1c6dffd894-cyclic-implicit-abouts.json",
+		"testImplicitlyClassifiedBy/annotations.json",
+	} {
+		assert.Contains(t, logs.String(), skipped)
+	}
+	assert.NotContains(t, logs.String(), "Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-pac.json")
+}
+
+func TestFixturesDriverReadUnknownContent(t *testing.T) {
+	fd, err := NewFixturesDriver("./testdata/impliedBy", "http://api.ft.com")
+	require.NoError(t, err)
+
+	res, err := fd.read(context.Background(), "00000000-0000-0000-0000-000000000000", nil)
+	assert.NoError(t, err)
+	assert.False(t, res.found)
+}
+
+func TestNewFixturesDriverUnknownConceptType(t *testing.T) {
+	dir := t.TempDir()
+	concept := `{"prefUUID": "b0c3f2f4-4a5b-4cd5-9d7a-1e0e55c6f1a2", "type": "Widget", "sourceRepresentations": []}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "widget.json"), []byte(concept), 0o600))
+
+	_, err := NewFixturesDriver(dir, "http://api.ft.com")
+	assert.ErrorContains(t, err, `unknown concept type "Widget"`)
+}
+
+func TestRelationshipName(t *testing.T) {
+	tests := map[string]string{
+		"isClassifiedBy": "IS_CLASSIFIED_BY",
+		"about":          "ABOUT",
+		"hasDisplayTag":  "HAS_DISPLAY_TAG",
+		"http://www.ft.com/ontology/annotation/majorMentions": "MAJOR_MENTIONS",
+		"IMPLICITLY_ABOUT": "IMPLICITLY_ABOUT",
+	}
+	for predicate, expected := range tests {
+		t.Run(predicate, func(t *testing.T) {
+			assert.Equal(t, expected, relationshipName(predicate))
+		})
+	}
+}
+
+func readFixture(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v), fmt.Sprintf("decoding %s", path))
+}
@@ -2,29 +2,46 @@ package annotations
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Financial-Times/go-logger/v2"
+	tid "github.com/Financial-Times/transactionid-utils-go"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const Neo4jBookmarkHeader = "Neo4j-Bookmark"
 
 // HandlerCtx contains objects needed from the annotations http handlers and is being passed to them as param
 type HandlerCtx struct {
-	AnnotationsDriver  driver
-	CacheControlHeader string
-	Log                *logger.UPPLogger
+	AnnotationsDriver driver
+	Log               *logger.UPPLogger
+	// ReadLimiter caps the concurrent reads of the annotations, if set
+	ReadLimiter *ReadLimiter
+
+	settings atomic.Pointer[Settings]
+	reloadMu sync.Mutex
 }
 
-func NewHandlerCtx(d driver, ch string, log *logger.UPPLogger) *HandlerCtx {
-	return &HandlerCtx{
-		AnnotationsDriver:  d,
-		CacheControlHeader: ch,
-		Log:                log,
+func NewHandlerCtx(d driver, settings *Settings, log *logger.UPPLogger) *HandlerCtx {
+	hctx := &HandlerCtx{
+		AnnotationsDriver: d,
+		Log:               log,
 	}
+	hctx.settings.Store(settings)
+	return hctx
 }
 
 // MethodNotAllowedHandler handles 405
@@ -37,93 +54,239 @@ func GetAnnotations(hctx *HandlerCtx) func(http.ResponseWriter, *http.Request) {
 		vars := mux.Vars(r)
 		uuid := vars["uuid"]
 
-		bookmark := r.Header.Get(Neo4jBookmarkHeader)
+		transactionID := tid.GetTransactionIDFromRequest(r)
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx = tid.TransactionAwareContext(ctx, transactionID)
+		ctx, span := startSpan(ctx, "GetAnnotations", trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String(contentUUIDAttribute, uuid),
+			attribute.String(transactionIDAttribute, transactionID),
+		))
+		defer span.End()
+
+		bookmarks := parseBookmarks(r.Header.Values(Neo4jBookmarkHeader))
 
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 		if uuid == "" {
-			http.Error(w, "uuid required", http.StatusBadRequest)
+			writeErrorResponse(hctx, w, newErrorResponse(http.StatusBadRequest, codeInvalidParameter, "uuid required").forTransaction(transactionID))
 			return
 		}
 
 		params := r.URL.Query()
+		settings := hctx.Settings()
 
 		var ok bool
 		var lifecycleParams []string
 		if lifecycleParams, ok = params["lifecycle"]; ok {
-			err := validateLifecycleParams(lifecycleParams)
+			err := settings.lifecyclePolicy().validateParams(lifecycleParams)
 			if err != nil {
-				hctx.Log.WithError(err).Error("invalid query parameter")
-				w.WriteHeader(http.StatusBadRequest)
-				msg := `{"message":"invalid query parameter"}`
-				if _, err = w.Write([]byte(msg)); err != nil {
-					hctx.Log.WithError(err).Errorf("Error while writing response: %s", msg)
-				}
+				writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
 				return
 			}
+			span.SetAttributes(attribute.StringSlice(lifecycleParamsAttribute, lifecycleParams))
+		}
+
+		publications, err := settings.publicationRegistry().resolveParams("publication", params["publication"])
+		if err != nil {
+			writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
+			return
+		}
+		inclusions, err := inclusionFilters(params)
+		if err != nil {
+			writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
+			return
+		}
+		exclusions, err := exclusionFilters(params, settings.publicationRegistry())
+		if err != nil {
+			writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
+			return
+		}
+		deprecatedMode, err := parseDeprecatedMode(params.Get("deprecated"))
+		if err != nil {
+			writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
+			return
+		}
+		showPublication, err := parseBoolParam(params, "showPublication")
+		if err != nil {
+			writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
+			return
+		}
+		showWarnings, err := parseBoolParam(params, "showWarnings")
+		if err != nil {
+			writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
+			return
+		}
+
+		bookmarkMode, err := parseBookmarkMode(r.Header.Get(Neo4jBookmarkModeHeader))
+		if err != nil {
+			writeInvalidQueryParam(hctx, w, uuid, transactionID, err)
+			return
+		}
+
+		release, err := hctx.ReadLimiter.acquire(r)
+		if errors.Is(err, errReadCancelled) {
+			span.AddEvent("read cancelled")
+			// the client went away, so there is no one left to respond to
+			hctx.Log.WithError(err).WithUUID(uuid).WithTransactionID(transactionID).Debug("request cancelled while its read was queued")
+			return
+		}
+		if err != nil {
+			span.AddEvent("read shed")
+			writeShedRead(hctx, w, uuid, transactionID, err)
+			return
 		}
+		defer release()
 
-		annotations, found, err := hctx.AnnotationsDriver.read(uuid, bookmark)
+		res, err := hctx.AnnotationsDriver.read(ctx, uuid, bookmarks)
+		if len(bookmarks) > 0 && bookmarkMode == bookmarkModeBestEffort && readFailureKind(err) == readFailureBookmarkTimeout {
+			hctx.Log.WithError(err).WithUUID(uuid).WithTransactionID(transactionID).Warn("timed out waiting for the bookmark, reading without it")
+			span.AddEvent("bookmark fallback")
+			bookmarkFallbacks.Inc()
+			w.Header().Set(Neo4jBookmarkConsistencyHeader, bookmarkNotGuaranteed)
+			res, err = hctx.AnnotationsDriver.read(ctx, uuid, nil)
+		}
+		var unmappedErr *unmappedAnnotationsError
+		if errors.As(err, &unmappedErr) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "annotations could not be mapped")
+			logWarnings(hctx, uuid, transactionID, unmappedErr.warnings)
+			writeContentError(hctx, w, http.StatusInternalServerError, codeUnmappableAnnotations, uuid, transactionID, "Annotations for content with uuid %s could not be mapped")
+			return
+		}
 		if err != nil {
-			hctx.Log.WithError(err).WithUUID(uuid).Error("failed getting annotations for content")
-			writeResponseError(hctx, w, http.StatusServiceUnavailable, uuid, `{"message":"Error getting annotations for content with uuid %s"}`)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed getting annotations for content")
+			writeReadError(hctx, w, uuid, transactionID, "failed getting annotations for content", err)
 			return
 		}
-		if !found {
-			writeResponseError(hctx, w, http.StatusNotFound, uuid, `{"message":"No annotations found for content with uuid %s."}`)
+		// the bookmarks of the read let the clients chain causally consistent reads, even if nothing is found
+		for _, b := range res.bookmarks {
+			w.Header().Add(Neo4jBookmarkHeader, b)
+		}
+		logWarnings(hctx, uuid, transactionID, res.warnings)
+		if !res.found {
+			writeContentError(hctx, w, http.StatusNotFound, codeContentNotFound, uuid, transactionID, "No annotations found for content with uuid %s.")
 			return
 		}
 
-		lifecycleFilter := newLifecycleFilter(withLifecycles(lifecycleParams))
-		predicateFilter := NewAnnotationsPredicateFilter()
-		showPublication := false
-		if showPublicationParam := params.Get("showPublication"); showPublicationParam != "" {
-			showPublication, err = strconv.ParseBool(showPublicationParam)
+		var successors map[string]Annotation
+		if uuids := deprecatedConceptUUIDs(res.annotations); deprecatedMode == deprecatedReplace && len(uuids) > 0 {
+			successors, err = readSuccessors(ctx, hctx.AnnotationsDriver, uuids)
+			if errors.Is(err, errSuccessorsNotSupported) {
+				writeContentError(hctx, w, http.StatusNotImplemented, codeNotSupported, uuid, transactionID, "Replacing deprecated concepts of content with uuid %s is not supported by the configured backend")
+				return
+			}
 			if err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				msg := `{"message":"showPublication query parameter is not a boolean"}`
-				if _, err = w.Write([]byte(msg)); err != nil {
-					hctx.Log.WithError(err).Errorf("Error while writing response: %s", msg)
-				}
+				span.RecordError(err)
+				writeReadError(hctx, w, uuid, transactionID, "failed resolving the successors of deprecated concepts", err)
 				return
 			}
 		}
-		publicationFilter := newPublicationFilter(withPublication(params["publication"], showPublication))
-		chain := newAnnotationsFilterChain(lifecycleFilter, predicateFilter, publicationFilter)
 
-		annotations = chain.doNext(annotations)
+		lifecycleFilter := newLifecycleFilter(withLifecyclePolicy(settings.LifecyclePolicy), withLifecycles(lifecycleParams))
+		predicateFilter := NewAnnotationsPredicateFilter(withPredicateRules(settings.PredicateRules))
+		publicationFilter := newPublicationFilter(withPublicationRegistry(settings.PublicationRegistry), withPublication(publications, showPublication))
+		filters := append([]annotationsFilter{lifecycleFilter, newDeprecatedFilter(deprecatedMode, successors), predicateFilter}, inclusions...)
+		filters = append(filters, exclusions...)
+		chain := newAnnotationsFilterChain(append(filters, publicationFilter)...)
+
+		annotations := chain.run(ctx, res.annotations)
+		span.SetAttributes(attribute.Int(annotationsCountAttribute, len(annotations)))
 		if len(annotations) == 0 {
-			writeResponseError(hctx, w, http.StatusNotFound, uuid, `{"message":"No annotations found for content with uuid %s for the specified filters."}`)
+			writeContentError(hctx, w, http.StatusNotFound, codeNoAnnotationsAfterFiltering, uuid, transactionID, "No annotations found for content with uuid %s for the specified filters.")
 			return
 		}
 
-		w.Header().Set("Cache-Control", hctx.CacheControlHeader)
-		w.WriteHeader(http.StatusOK)
-
-		if err = json.NewEncoder(w).Encode(annotations); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			msg := fmt.Sprintf(`{"message":"Error parsing annotations for content with uuid %s, err=%s"}`, uuid, err.Error())
-			hctx.Log.Error(msg)
-			if _, err = w.Write([]byte(msg)); err != nil {
-				hctx.Log.WithError(err).Errorf("Error while writing response: %s", msg)
+		var body interface{} = annotations
+		if showWarnings {
+			warnings := res.warnings
+			if warnings == nil {
+				warnings = []Warning{}
 			}
+			body = AnnotationsWithWarnings{Annotations: annotations, Warnings: warnings}
+		}
+
+		// the body is encoded before writing the status, so that failing to encode it can still be reported as an error
+		resp, err := json.Marshal(body)
+		if err != nil {
+			hctx.Log.WithError(err).WithUUID(uuid).WithTransactionID(transactionID).Error("failed encoding annotations")
+			writeContentError(hctx, w, http.StatusInternalServerError, codeInternalError, uuid, transactionID, "Error encoding annotations for content with uuid %s")
+			return
+		}
+
+		annotationsReturned.Observe(float64(len(annotations)))
+
+		w.Header().Set("Cache-Control", settings.CacheControlHeader)
+		w.WriteHeader(http.StatusOK)
+		if _, err = w.Write(append(resp, '\n')); err != nil {
+			hctx.Log.WithError(err).WithUUID(uuid).WithTransactionID(transactionID).Error("failed writing annotations response")
 		}
 	}
 }
 
-func writeResponseError(hctx *HandlerCtx, w http.ResponseWriter, status int, uuid, message string) {
-	w.WriteHeader(status)
-	msg := fmt.Sprintf(message, uuid)
-	if _, err := w.Write([]byte(msg)); err != nil {
-		hctx.Log.WithError(err).Errorf("Error while writing response: %s", msg)
+// parseBoolParam parses the optional boolean query parameter, which defaults to false.
+func parseBoolParam(params url.Values, param string) (bool, error) {
+	value := params.Get(param)
+	if value == "" {
+		return false, nil
 	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &invalidQueryParamError{param: param, value: value}
+	}
+	return b, nil
 }
 
-func validateLifecycleParams(lifecycleParams []string) error {
-	for _, lp := range lifecycleParams {
-		if _, ok := lifecycleMap[lp]; !ok {
-			return fmt.Errorf("invalid lifecycle value: %s", lp)
-		}
+// writeReadError logs the failure to read from the backend and responds according to its kind. Only the failures
+// which may succeed when retried get a 503, so that clients do not retry the requests which can never succeed.
+func writeReadError(hctx *HandlerCtx, w http.ResponseWriter, uuid, transactionID, msg string, err error) {
+	kind := readFailureKind(err)
+	log := hctx.Log.WithError(err).WithUUID(uuid).WithTransactionID(transactionID).WithField("failure", kind)
+	switch kind {
+	case readFailureInvalidBookmark:
+		log.Warn(msg)
+		writeContentError(hctx, w, http.StatusBadRequest, codeInvalidBookmark, uuid, transactionID, "Invalid "+Neo4jBookmarkHeader+" header for content with uuid %s: the bookmark was rejected by Neo4j")
+	case readFailureBookmarkTimeout:
+		log.Warn(msg)
+		writeContentError(hctx, w, http.StatusServiceUnavailable, codeBookmarkTimeout, uuid, transactionID, "Timed out waiting for Neo4j to catch up with the "+Neo4jBookmarkHeader+" header for content with uuid %s")
+	case readFailureQuery:
+		log.Error(msg)
+		writeContentError(hctx, w, http.StatusInternalServerError, codeQueryFailed, uuid, transactionID, "Error querying annotations for content with uuid %s")
+	default:
+		log.Error(msg)
+		writeContentError(hctx, w, http.StatusServiceUnavailable, codeBackendUnavailable, uuid, transactionID, "Error getting annotations for content with uuid %s")
+	}
+}
+
+// writeContentError responds with the error that occurred for the content with uuid. The detail is formatted with the uuid.
+func writeContentError(hctx *HandlerCtx, w http.ResponseWriter, status int, code, uuid, transactionID, detail string) {
+	writeErrorResponse(hctx, w, newErrorResponse(status, code, fmt.Sprintf(detail, uuid)).forContent(uuid).forTransaction(transactionID))
+}
+
+// logWarnings logs the annotations which could not be mapped in a single line per request, with their count
+// and reasons, and each one of them at debug level.
+func logWarnings(hctx *HandlerCtx, uuid, transactionID string, warnings []Warning) {
+	if len(warnings) == 0 {
+		return
 	}
 
-	return nil
+	reasons := make(map[string]int)
+	for _, warning := range warnings {
+		reasons[warning.Reason]++
+		hctx.Log.WithUUID(uuid).
+			WithTransactionID(transactionID).
+			WithField("conceptUUID", warning.ConceptID).
+			WithField("relationship", warning.Relationship).
+			WithField("reason", warning.Reason).
+			Debugf("annotation could not be mapped: %s", warning.Message)
+	}
+
+	counts := make([]string, 0, len(reasons))
+	for reason, count := range reasons {
+		counts = append(counts, fmt.Sprintf("%s=%d", reason, count))
+	}
+	slices.Sort(counts)
+	hctx.Log.WithUUID(uuid).
+		WithTransactionID(transactionID).
+		WithField("reasons", strings.Join(counts, ",")).
+		Warnf("%d annotations could not be mapped", len(warnings))
 }
@@ -1,16 +1,22 @@
 package annotations
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/gorilla/mux"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -29,49 +35,79 @@ func TestGetHandler(t *testing.T) {
 			name: "Success",
 			req:  newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID)),
 			annotationsDriver: mockDriver{
-				readFunc: func(string, string) (anns Annotations, found bool, err error) {
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
 					return []Annotation{}, true, nil
 				},
 			},
 			expectedStatusCode: http.StatusNotFound,
-			expectedBody:       "{\"message\":\"No annotations found for content with uuid 12345 for the specified filters.\"}",
+			expectedBody:       errorBody(http.StatusNotFound, codeNoAnnotationsAfterFiltering, knownUUID, "No annotations found for content with uuid 12345 for the specified filters."),
 		},
 		{
 			name: "NotFound",
 			req:  newRequest(fmt.Sprintf("/content/%s/annotations", "99999")),
 			annotationsDriver: mockDriver{
-				readFunc: func(string, string) (anns Annotations, found bool, err error) {
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
 					return []Annotation{}, false, nil
 				},
 			},
 			expectedStatusCode: http.StatusNotFound,
-			expectedBody:       message("No annotations found for content with uuid 99999."),
+			expectedBody:       errorBody(http.StatusNotFound, codeContentNotFound, "99999", "No annotations found for content with uuid 99999."),
 		},
 		{
 			name: "ReadError",
 			req:  newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID)),
 			annotationsDriver: mockDriver{
-				readFunc: func(string, string) (anns Annotations, found bool, err error) {
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
 					return nil, false, errors.New("TEST failing to READ")
 				},
 			},
 			expectedStatusCode: http.StatusServiceUnavailable,
-			expectedBody:       message("Error getting annotations for content with uuid 12345"),
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeBackendUnavailable, knownUUID, "Error getting annotations for content with uuid 12345"),
+		},
+		{
+			name: "InvalidBookmark",
+			req:  newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID)),
+			annotationsDriver: mockDriver{
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
+					return nil, false, newReadError(&neo4j.Neo4jError{Code: "Neo.ClientError.Transaction.InvalidBookmark", Msg: "TEST invalid bookmark"})
+				},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       errorBody(http.StatusBadRequest, codeInvalidBookmark, knownUUID, "Invalid Neo4j-Bookmark header for content with uuid 12345: the bookmark was rejected by Neo4j"),
+		},
+		{
+			name: "BookmarkTimeout",
+			req:  newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID)),
+			annotationsDriver: mockDriver{
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
+					return nil, false, newReadError(&neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.BookmarkTimeout", Msg: "TEST bookmark timeout"})
+				},
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeBookmarkTimeout, knownUUID, "Timed out waiting for Neo4j to catch up with the Neo4j-Bookmark header for content with uuid 12345"),
+		},
+		{
+			name: "QueryError",
+			req:  newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID)),
+			annotationsDriver: mockDriver{
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
+					return nil, false, newReadError(&neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError", Msg: "TEST syntax error"})
+				},
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       errorBody(http.StatusInternalServerError, codeQueryFailed, knownUUID, "Error querying annotations for content with uuid 12345"),
 		},
 	}
 
 	for _, test := range tests {
-		hctx := &HandlerCtx{
-			AnnotationsDriver:  test.annotationsDriver,
-			CacheControlHeader: "test-header",
-			Log:                logger.NewUPPLogger("test-public-annotations-api", "panic"),
-		}
+		hctx := NewHandlerCtx(test.annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "panic"))
 		rec := httptest.NewRecorder()
 		r := mux.NewRouter()
 		r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
 		r.ServeHTTP(rec, test.req)
 		assert.True(t, test.expectedStatusCode == rec.Code, fmt.Sprintf("%s: Wrong response code, was %d, should be %d", test.name, rec.Code, test.expectedStatusCode))
 		assert.JSONEq(t, test.expectedBody, rec.Body.String(), fmt.Sprintf("%s: Wrong body", test.name))
+		assert.Equal(t, "application/problem+json; charset=UTF-8", rec.Header().Get("Content-Type"), fmt.Sprintf("%s: Wrong content type", test.name))
 	}
 }
 
@@ -85,27 +121,27 @@ func TestGetHandlerWithLifecycleQueryParams(t *testing.T) {
 	}{
 		"request with valid lifecycle parameter should succeed": {
 			annotationsDriver: mockDriver{
-				readFunc: func(string, string) (anns Annotations, found bool, err error) {
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
 					return []Annotation{}, true, nil
 				},
 			},
 			lifecycleParams:    "lifecycle=pac",
 			expectedStatusCode: http.StatusNotFound,
-			expectedBody:       "{\"message\":\"No annotations found for content with uuid 12345 for the specified filters.\"}",
+			expectedBody:       errorBody(http.StatusNotFound, codeNoAnnotationsAfterFiltering, knownUUID, "No annotations found for content with uuid 12345 for the specified filters."),
 		},
 		"request with invalid lifecycle parameter should fail": {
 			annotationsDriver: mockDriver{
-				readFunc: func(string, string) (anns Annotations, found bool, err error) {
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
 					return []Annotation{}, true, nil
 				},
 			},
 			lifecycleParams:    "lifecycle=invalid",
 			expectedStatusCode: http.StatusBadRequest,
-			expectedBody:       `{"message":"invalid query parameter"}`,
+			expectedBody:       invalidParamBody("lifecycle", "invalid"),
 		},
 		"request with lifecycle parameters should apply additional filtering": {
 			annotationsDriver: mockDriver{
-				readFunc: func(string, string) (anns Annotations, found bool, err error) {
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
 					return []Annotation{pacAnnotationA, pacAnnotationB, v1AnnotationA, v1AnnotationB, v2AnnotationA, v2AnnotationB}, true, nil
 				},
 			},
@@ -126,15 +162,8 @@ func TestGetHandlerWithLifecycleQueryParams(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			hctx := &HandlerCtx{
-				AnnotationsDriver:  tc.annotationsDriver,
-				CacheControlHeader: "test-header",
-				Log:                logger.NewUPPLogger("test-public-annotations-api", "PANIC"),
-			}
-			req, err := http.NewRequest("GET", fmt.Sprintf("/content/%s/annotations?%s", knownUUID, tc.lifecycleParams), nil)
-			if err != nil {
-				t.Fatal(err)
-			}
+			hctx := NewHandlerCtx(tc.annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+			req := newRequest(fmt.Sprintf("/content/%s/annotations?%s", knownUUID, tc.lifecycleParams))
 
 			rec := httptest.NewRecorder()
 			r := mux.NewRouter()
@@ -147,7 +176,7 @@ func TestGetHandlerWithLifecycleQueryParams(t *testing.T) {
 			}
 
 			actualAnns := Annotations{}
-			err = json.Unmarshal(rec.Body.Bytes(), &actualAnns)
+			err := json.Unmarshal(rec.Body.Bytes(), &actualAnns)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -156,6 +185,157 @@ func TestGetHandlerWithLifecycleQueryParams(t *testing.T) {
 	}
 }
 
+func TestGetAnnotationsPublication(t *testing.T) {
+	registry := &PublicationRegistry{
+		Publications: []Publication{
+			{UUID: ftPink, ShortName: "ft", DisplayName: "Financial Times"},
+			{UUID: sv, ShortName: "sv", DisplayName: "Sustainable Views"},
+		},
+		Default: "sv",
+	}
+
+	tests := map[string]struct {
+		registry            *PublicationRegistry
+		query               string
+		expectedStatusCode  int
+		expectedBody        string
+		expectedAnnotations Annotations
+	}{
+		"short name": {
+			query:               "publication=SV",
+			expectedStatusCode:  http.StatusOK,
+			expectedAnnotations: Annotations{{ID: annotationA.ID, Predicate: ABOUT}, {ID: annotationC.ID, Predicate: ABOUT}},
+		},
+		"uuid and short name of the default publication": {
+			query:               "publication=ft&publication=" + ftPink,
+			expectedStatusCode:  http.StatusOK,
+			expectedAnnotations: Annotations{{ID: annotationB.ID, Predicate: MENTIONS}, {ID: annotationD.ID, Predicate: ABOUT}},
+		},
+		"configured default publication": {
+			registry:            registry,
+			query:               "publication=sv",
+			expectedStatusCode:  http.StatusOK,
+			expectedAnnotations: Annotations{{ID: annotationA.ID, Predicate: ABOUT}, {ID: annotationC.ID, Predicate: ABOUT}, {ID: annotationD.ID, Predicate: ABOUT}},
+		},
+		"publication names": {
+			query:              "publication=ft&showPublication=true",
+			expectedStatusCode: http.StatusOK,
+			expectedAnnotations: Annotations{
+				{ID: annotationB.ID, Predicate: MENTIONS, Publication: []string{ftPink}, PublicationNames: []string{"Financial Times"}},
+				{ID: annotationD.ID, Predicate: ABOUT},
+			},
+		},
+		"unknown publication": {
+			query:              "publication=ft&publication=" + st,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       invalidParamBody("publication", st),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			annotationsDriver := mockDriver{
+				readFunc: func(context.Context, string, []string) (Annotations, bool, error) {
+					return []Annotation{annotationA, annotationB, annotationC, annotationD}, true, nil
+				},
+			}
+			hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header", PublicationRegistry: tc.registry}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+
+			rec := httptest.NewRecorder()
+			r := mux.NewRouter()
+			r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+			r.ServeHTTP(rec, newRequest(fmt.Sprintf("/content/%s/annotations?%s", knownUUID, tc.query)))
+
+			assert.Equal(t, tc.expectedStatusCode, rec.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+				return
+			}
+			actualAnns := Annotations{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actualAnns))
+			assert.ElementsMatch(t, tc.expectedAnnotations, actualAnns)
+		})
+	}
+}
+
+func TestGetAnnotationsQueryFilters(t *testing.T) {
+	tests := map[string]struct {
+		query               string
+		expectedStatusCode  int
+		expectedBody        string
+		expectedAnnotations Annotations
+	}{
+		"included types": {
+			query:               "type=Person",
+			expectedStatusCode:  http.StatusOK,
+			expectedAnnotations: Annotations{authorAnnotation, displayTagAnnotation},
+		},
+		"included and excluded predicates": {
+			query:               "type=Person&excludePredicate=hasDisplayTag",
+			expectedStatusCode:  http.StatusOK,
+			expectedAnnotations: Annotations{authorAnnotation},
+		},
+		"excluded predicates and types": {
+			query:               "excludePredicate=hasAuthor&excludeType=Brand",
+			expectedStatusCode:  http.StatusOK,
+			expectedAnnotations: Annotations{displayTagAnnotation, companyAnnotation},
+		},
+		"everything excluded": {
+			query:              "excludeType=Concept",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       errorBody(http.StatusNotFound, codeNoAnnotationsAfterFiltering, knownUUID, "No annotations found for content with uuid 12345 for the specified filters."),
+		},
+		"unknown predicate": {
+			query:              "predicate=hasEditor",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       invalidParamBody("predicate", "hasEditor"),
+		},
+		"unknown excluded predicate": {
+			query:              "excludePredicate=hasEditor",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       invalidParamBody("excludePredicate", "hasEditor"),
+		},
+		"unknown type": {
+			query:              "type=Persn",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       invalidParamBody("type", "Persn"),
+		},
+		"unknown excluded type": {
+			query:              "excludeType=Persn",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       invalidParamBody("excludeType", "Persn"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			annotationsDriver := mockDriver{
+				readFunc: func(context.Context, string, []string) (Annotations, bool, error) {
+					return []Annotation{authorAnnotation, displayTagAnnotation, companyAnnotation, brandAnnotation}, true, nil
+				},
+			}
+			hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+
+			rec := httptest.NewRecorder()
+			r := mux.NewRouter()
+			r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+			r.ServeHTTP(rec, newRequest(fmt.Sprintf("/content/%s/annotations?%s", knownUUID, tc.query)))
+
+			assert.Equal(t, tc.expectedStatusCode, rec.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+				return
+			}
+			actualAnns := Annotations{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actualAnns))
+			for i := range tc.expectedAnnotations {
+				tc.expectedAnnotations[i].Publication = nil
+			}
+			assert.ElementsMatch(t, tc.expectedAnnotations, actualAnns)
+		})
+	}
+}
+
 func TestMethodeNotFound(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -168,7 +348,7 @@ func TestMethodeNotFound(t *testing.T) {
 			name: "NotFound",
 			req:  newRequest(fmt.Sprintf("/content/%s/annotations/", knownUUID)),
 			annotationsDriver: mockDriver{
-				readFunc: func(string, string) (anns Annotations, found bool, err error) {
+				readFunc: func(context.Context, string, []string) (anns Annotations, found bool, err error) {
 					return []Annotation{}, true, nil
 				},
 			},
@@ -178,11 +358,7 @@ func TestMethodeNotFound(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		hctx := &HandlerCtx{
-			AnnotationsDriver:  test.annotationsDriver,
-			CacheControlHeader: "test-header",
-			Log:                logger.NewUPPInfoLogger("test-public-annotations-api"),
-		}
+		hctx := NewHandlerCtx(test.annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPInfoLogger("test-public-annotations-api"))
 		rec := httptest.NewRecorder()
 		r := mux.NewRouter()
 		r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
@@ -205,8 +381,8 @@ func TestGetHandlerWithSetBookmarkHeader(t *testing.T) {
 			name: "Empty bookmark",
 			req:  newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID)),
 			annotationsDriver: mockDriver{
-				readFunc: func(uuid string, bookmark string) (anns Annotations, found bool, err error) {
-					if bookmark != "" {
+				readFunc: func(_ context.Context, uuid string, bookmarks []string) (anns Annotations, found bool, err error) {
+					if len(bookmarks) != 0 {
 						return []Annotation{}, false, errors.New("unexpected bookmark")
 					}
 
@@ -226,8 +402,8 @@ func TestGetHandlerWithSetBookmarkHeader(t *testing.T) {
 			name: "Not empty bookmark",
 			req:  newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID)),
 			annotationsDriver: mockDriver{
-				readFunc: func(uuid string, bookmark string) (anns Annotations, found bool, err error) {
-					if bookmark != "FB:kcwQnrEEnFpfSJ2PtiykK/JNh8oBozhIkA==" {
+				readFunc: func(_ context.Context, uuid string, bookmarks []string) (anns Annotations, found bool, err error) {
+					if !slices.Equal(bookmarks, []string{"FB:kcwQnrEEnFpfSJ2PtiykK/JNh8oBozhIkA=="}) {
 						return []Annotation{}, false, errors.New("unexpected bookmark")
 					}
 
@@ -246,11 +422,7 @@ func TestGetHandlerWithSetBookmarkHeader(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		hctx := &HandlerCtx{
-			AnnotationsDriver:  test.annotationsDriver,
-			CacheControlHeader: "test-header",
-			Log:                logger.NewUPPInfoLogger("test-public-annotations-api"),
-		}
+		hctx := NewHandlerCtx(test.annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPInfoLogger("test-public-annotations-api"))
 		rec := httptest.NewRecorder()
 		r := mux.NewRouter()
 		r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
@@ -266,30 +438,133 @@ func TestGetHandlerWithSetBookmarkHeader(t *testing.T) {
 	}
 }
 
+func TestGetHandlerWithMappingWarnings(t *testing.T) {
+	warning := Warning{
+		ConceptID:    "0ab61bfc-a2b1-4b08-a864-4233fd72f250",
+		Relationship: "UNKNOWN",
+		Reason:       mappingFailurePredicate,
+		Message:      "not a valid annotation type",
+	}
+	tests := map[string]struct {
+		query              string
+		readResultFunc     func(context.Context, string, []string) (readResult, error)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"warnings are not returned by default": {
+			readResultFunc: func(context.Context, string, []string) (readResult, error) {
+				return readResult{annotations: Annotations{pacAnnotationA}, found: true, warnings: []Warning{warning}}, nil
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"predicate":"http://www.ft.com/ontology/annotation/about","id":"6bbd0457-15ab-4ddc-ab82-0cd5b8d9ce18","apiUrl":"","types":null}]`,
+		},
+		"warnings are returned when requested": {
+			query: "showWarnings=true",
+			readResultFunc: func(context.Context, string, []string) (readResult, error) {
+				return readResult{annotations: Annotations{pacAnnotationA}, found: true, warnings: []Warning{warning}}, nil
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/about","id":"6bbd0457-15ab-4ddc-ab82-0cd5b8d9ce18","apiUrl":"","types":null}],
+				"warnings":[{"conceptId":"0ab61bfc-a2b1-4b08-a864-4233fd72f250","relationship":"UNKNOWN","reason":"unknown_predicate","message":"not a valid annotation type"}]}`,
+		},
+		"empty warnings are returned when requested": {
+			query: "showWarnings=true",
+			readResultFunc: func(context.Context, string, []string) (readResult, error) {
+				return readResult{annotations: Annotations{pacAnnotationA}, found: true}, nil
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/about","id":"6bbd0457-15ab-4ddc-ab82-0cd5b8d9ce18","apiUrl":"","types":null}],"warnings":[]}`,
+		},
+		"invalid showWarnings parameter": {
+			query: "showWarnings=maybe",
+			readResultFunc: func(context.Context, string, []string) (readResult, error) {
+				return readResult{annotations: Annotations{pacAnnotationA}, found: true}, nil
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       invalidParamBody("showWarnings", "maybe"),
+		},
+		"strict mapping failure": {
+			readResultFunc: func(context.Context, string, []string) (readResult, error) {
+				return readResult{}, &unmappedAnnotationsError{contentUUID: knownUUID, warnings: []Warning{warning}}
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       errorBody(http.StatusInternalServerError, codeUnmappableAnnotations, knownUUID, "Annotations for content with uuid 12345 could not be mapped"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hctx := NewHandlerCtx(mockDriver{readResultFunc: tc.readResultFunc}, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+			rec := httptest.NewRecorder()
+			r := mux.NewRouter()
+			r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+			r.ServeHTTP(rec, newRequest(fmt.Sprintf("/content/%s/annotations?%s", knownUUID, tc.query)))
+
+			assert.Equal(t, tc.expectedStatusCode, rec.Code, "Wrong response code")
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String(), "Wrong response body")
+		})
+	}
+}
+
+func TestLogWarnings(t *testing.T) {
+	var out bytes.Buffer
+	log := logger.NewUPPLogger("test-public-annotations-api", "WARN")
+	log.Out = &out
+	hctx := NewHandlerCtx(mockDriver{}, &Settings{}, log)
+
+	logWarnings(hctx, knownUUID, "tid_test", nil)
+	assert.Empty(t, out.String())
+
+	logWarnings(hctx, knownUUID, "tid_test", []Warning{
+		{ConceptID: "a", Relationship: "UNKNOWN", Reason: mappingFailurePredicate},
+		{ConceptID: "b", Relationship: "ABOUT", Reason: mappingFailureType},
+		{ConceptID: "c", Relationship: "UNKNOWN", Reason: mappingFailurePredicate},
+	})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 1, "the warnings of a request are logged in a single line")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "3 annotations could not be mapped", entry["msg"])
+	assert.Equal(t, "unknown_predicate=2,unknown_type=1", entry["reasons"])
+}
+
 func newRequest(url string) *http.Request {
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		panic(err)
 	}
 	req.Header.Add("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "tid_test")
 	return req
 }
 
-func message(errMsg string) string {
-	return fmt.Sprintf("{\"message\": \"%s\"}\n", errMsg)
+// errorBody is the problem details response to a request made with newRequest.
+func errorBody(status int, code, uuid, detail string) string {
+	return fmt.Sprintf(`{"type":"about:blank","title":%q,"status":%d,"code":%q,"detail":%q,"instance":%q,"transactionId":"tid_test"}`,
+		http.StatusText(status), status, code, detail, uuid)
+}
+
+func invalidParamBody(param, value string) string {
+	return fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid-parameter","detail":"invalid %s query parameter: %s","instance":%q,"param":%q,"transactionId":"tid_test"}`,
+		param, value, knownUUID, param)
 }
 
 type mockDriver struct {
-	readFunc              func(string, string) (Annotations, bool, error)
+	readFunc              func(context.Context, string, []string) (Annotations, bool, error)
+	readResultFunc        func(context.Context, string, []string) (readResult, error)
 	checkConnectivityFunc func() error
 }
 
-func (md mockDriver) read(contentUUID, bookmark string) (Annotations, bool, error) {
+func (md mockDriver) read(ctx context.Context, contentUUID string, bookmarks []string) (readResult, error) {
+	if md.readResultFunc != nil {
+		return md.readResultFunc(ctx, contentUUID, bookmarks)
+	}
 	if md.readFunc == nil {
-		return nil, false, errors.New("not implemented")
+		return readResult{}, errors.New("not implemented")
 	}
 
-	return md.readFunc(contentUUID, bookmark)
+	anns, found, err := md.readFunc(ctx, contentUUID, bookmarks)
+	return readResult{annotations: anns, found: found}, err
 }
 
 func (md mockDriver) checkConnectivity() error {
@@ -1,6 +1,9 @@
 package annotations
 
 import (
+	"fmt"
+	"time"
+
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 	"github.com/Financial-Times/service-status-go/gtg"
 )
@@ -21,6 +24,95 @@ func HealthCheck(hctx *HandlerCtx) fthealth.Check {
 	}
 }
 
+// backendHealthDriver is implemented by the drivers reading from several backends, which are checked one by one.
+type backendHealthDriver interface {
+	healthChecks() []fthealth.Check
+}
+
+// HealthConfig configures the checks of the backend beyond its connectivity.
+type HealthConfig struct {
+	// ProbeThreshold is the latency of the probe query above which the backend is reported as slow
+	ProbeThreshold time.Duration
+	// CanaryContentUUID is the uuid of a piece of content expected to have annotations. No canary is read if empty.
+	CanaryContentUUID string
+	// ConnectivityInterval is how often the scheduled connectivity checks run, which the good-to-go status is served from
+	ConnectivityInterval time.Duration
+	// QueryInterval is how often the scheduled query latency, schema and canary content checks run
+	QueryInterval time.Duration
+}
+
+// ScheduleHealthChecks schedules the neo4j-cluster-health check, followed by the query latency, schema and canary content
+// checks the driver supports, and by a check of each backend of the driver, if it has several. The connectivity checks run
+// every ConnectivityInterval and the query ones every QueryInterval. They are returned along with a good-to-go status
+// served from the cached connectivity check, so that neither endpoint hits the backend on every request.
+func ScheduleHealthChecks(hs *HealthScheduler, hctx *HandlerCtx, cfg HealthConfig) ([]fthealth.Check, func() gtg.Status) {
+	connectivity := hs.Schedule(HealthCheck(hctx), cfg.ConnectivityInterval)
+	checks := []fthealth.Check{connectivity}
+	for _, c := range queryChecks(hctx, cfg) {
+		checks = append(checks, hs.Schedule(c, cfg.QueryInterval))
+	}
+	for _, c := range backendChecks(hctx) {
+		checks = append(checks, hs.Schedule(c, cfg.ConnectivityInterval))
+	}
+	return checks, GoodToGoFrom(connectivity)
+}
+
+// queryChecks returns the query latency, schema and canary content checks the driver supports.
+func queryChecks(hctx *HandlerCtx, cfg HealthConfig) []fthealth.Check {
+	var checks []fthealth.Check
+	if _, ok := hctx.AnnotationsDriver.(probeDriver); ok {
+		checks = append(checks, QueryLatencyCheck(hctx, cfg.ProbeThreshold), SchemaCheck(hctx))
+	}
+	if cfg.CanaryContentUUID != "" {
+		checks = append(checks, CanaryContentCheck(hctx, cfg.CanaryContentUUID))
+	}
+	return checks
+}
+
+// backendChecks returns a check of each backend of the driver, if it has several.
+func backendChecks(hctx *HandlerCtx) []fthealth.Check {
+	if d, ok := hctx.AnnotationsDriver.(backendHealthDriver); ok {
+		return d.healthChecks()
+	}
+	return nil
+}
+
+func QueryLatencyCheck(hctx *HandlerCtx, threshold time.Duration) fthealth.Check {
+	return fthealth.Check{
+		ID:               "neo4j-query-latency",
+		BusinessImpact:   "Public Annotations api requests are slow or time out",
+		Name:             "Check the latency of a probe query to Neo4j",
+		PanicGuide:       runbookURL,
+		Severity:         2,
+		TechnicalSummary: fmt.Sprintf("A probe query to Neo4j failed or took longer than %s, although Neo4j is reachable. Check the load of the Neo4j cluster.", threshold),
+		Checker:          ProbeChecker(hctx.AnnotationsDriver, threshold),
+	}
+}
+
+func SchemaCheck(hctx *HandlerCtx) fthealth.Check {
+	return fthealth.Check{
+		ID:               "neo4j-schema",
+		BusinessImpact:   "Public Annotations api requests are slow or time out",
+		Name:             "Check the Neo4j indexes and constraints the annotations queries rely on",
+		PanicGuide:       runbookURL,
+		Severity:         2,
+		TechnicalSummary: `An index or constraint the annotations queries look the content and concepts up by is missing, so the queries scan the graph. Recreate it in Neo4j.`,
+		Checker:          SchemaChecker(hctx.AnnotationsDriver),
+	}
+}
+
+func CanaryContentCheck(hctx *HandlerCtx, contentUUID string) fthealth.Check {
+	return fthealth.Check{
+		ID:               "canary-content",
+		BusinessImpact:   "Public Annotations api requests may return no annotations",
+		Name:             "Check the annotations of the canary content can be read",
+		PanicGuide:       runbookURL,
+		Severity:         2,
+		TechnicalSummary: fmt.Sprintf("Reading the annotations of the canary content %s failed or returned none. Check the annotations in Neo4j and the canaryContentUUID parameter of this service.", contentUUID),
+		Checker:          CanaryChecker(hctx.AnnotationsDriver, contentUUID),
+	}
+}
+
 func Neo4jChecker(annDriver driver) func() (string, error) {
 	return func() (string, error) {
 		err := annDriver.checkConnectivity()
@@ -32,9 +124,11 @@ func Neo4jChecker(annDriver driver) func() (string, error) {
 	}
 }
 
-func GoodToGo(hctx *HandlerCtx) func() gtg.Status {
+// GoodToGoFrom serves the good-to-go status from the check, which is expected to be a scheduled one.
+// The gtg handler responds with OK while the check passes, and with the error of the check otherwise.
+func GoodToGoFrom(check fthealth.Check) func() gtg.Status {
 	return func() gtg.Status {
-		if _, err := Neo4jChecker(hctx.AnnotationsDriver)(); err != nil {
+		if _, err := check.Checker(); err != nil {
 			return gtg.Status{GoodToGo: false, Message: err.Error()}
 		}
 		return gtg.Status{GoodToGo: true}
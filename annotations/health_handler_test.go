@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/service-status-go/httphandlers"
@@ -20,15 +21,14 @@ func TestGTGUnhealthyCluster(t *testing.T) {
 			return errors.New("test error")
 		},
 	}
-	hctx := &HandlerCtx{
-		AnnotationsDriver:  annotationsDriver,
-		CacheControlHeader: "test-header",
-		Log:                logger.NewUPPLogger("test-public-annotations-api", "PANIC"),
-	}
+	hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+	hs := NewHealthScheduler()
+	_, goodToGo := ScheduleHealthChecks(hs, hctx, HealthConfig{ConnectivityInterval: time.Minute, QueryInterval: time.Minute})
+	hs.run(hs.checks[0])
 
 	//create a responseRecorder
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(httphandlers.NewGoodToGoHandler(GoodToGo(hctx)))
+	handler := http.HandlerFunc(httphandlers.NewGoodToGoHandler(goodToGo))
 
 	// Our handlers satisfy http.Handler, so we can call their ServeHTTP method
 	// directly and pass in our Request and ResponseRecorder.
@@ -39,7 +39,7 @@ func TestGTGUnhealthyCluster(t *testing.T) {
 	// Series of verifications:
 	assert.Equal(t, http.StatusServiceUnavailable, actual.StatusCode, "status code")
 	assert.Equal(t, "no-cache", actual.Header.Get("Cache-Control"), "cache-control header")
-	assert.Equal(t, "test error", rr.Body.String(), "GTG response body")
+	assert.Equal(t, "test error (checked 0s ago)", rr.Body.String(), "GTG response body")
 }
 
 func TestGTGHealthyCluster(t *testing.T) {
@@ -50,14 +50,14 @@ func TestGTGHealthyCluster(t *testing.T) {
 			return nil
 		},
 	}
-	hctx := &HandlerCtx{
-		AnnotationsDriver:  annotationsDriver,
-		CacheControlHeader: "test-header",
-		Log:                logger.NewUPPLogger("test-public-annotations-api", "PANIC"),
-	}
+	hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+	hs := NewHealthScheduler()
+	_, goodToGo := ScheduleHealthChecks(hs, hctx, HealthConfig{ConnectivityInterval: time.Minute, QueryInterval: time.Minute})
+	hs.run(hs.checks[0])
+
 	//create a responseRecorder
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(httphandlers.NewGoodToGoHandler(GoodToGo(hctx)))
+	handler := http.HandlerFunc(httphandlers.NewGoodToGoHandler(goodToGo))
 
 	// Our handlers satisfy http.Handler, so we can call their ServeHTTP method
 	// directly and pass in our Request and ResponseRecorder.
@@ -0,0 +1,166 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cmneo4j "github.com/Financial-Times/cm-neo4j-driver"
+)
+
+// errProbeNotSupported is returned by the probeDriver decorators whose underlying driver cannot be probed.
+var errProbeNotSupported = errors.New("health probes are not supported by the driver")
+
+// probeDriver is implemented by the drivers able to check that their backend can serve the annotations queries,
+// rather than only being reachable.
+type probeDriver interface {
+	// probe runs a cheap query and returns how long it took
+	probe(ctx context.Context) (time.Duration, error)
+	// missingSchema returns the indexes and constraints the annotations queries rely on which do not exist
+	missingSchema(ctx context.Context) ([]string, error)
+}
+
+const probeCypher = `RETURN 1 AS ok`
+
+const (
+	showIndexesCypher     = `SHOW INDEXES YIELD labelsOrTypes, properties`
+	showConstraintsCypher = `SHOW CONSTRAINTS YIELD labelsOrTypes, properties`
+)
+
+// schemaEntry is an index or a constraint on a single property of a label.
+type schemaEntry struct {
+	label    string
+	property string
+}
+
+func (e schemaEntry) String() string {
+	return ":" + e.label + "(" + e.property + ")"
+}
+
+var (
+	// expectedIndexes are the indexes the annotations queries look the nodes up by
+	expectedIndexes = []schemaEntry{{label: "Content", property: "uuid"}, {label: "Concept", property: "prefUUID"}}
+	// expectedConstraints are the constraints written by content-rw-neo4j the annotations queries rely on
+	expectedConstraints = []schemaEntry{{label: "Content", property: "uuid"}}
+)
+
+type neoSchemaEntry struct {
+	LabelsOrTypes []string
+	Properties    []string
+}
+
+func (cd CypherDriver) probe(_ context.Context) (time.Duration, error) {
+	var results []struct{ Ok int }
+	query := &cmneo4j.Query{Cypher: probeCypher, Result: &results}
+
+	start := time.Now()
+	_, err := cd.driver.ReadMultiple([]*cmneo4j.Query{query}, nil)
+	latency := time.Since(start)
+	queryDuration.WithLabelValues("probe").Observe(latency.Seconds())
+	if err != nil {
+		return latency, fmt.Errorf("failed running the probe query: %w", err)
+	}
+	return latency, nil
+}
+
+func (cd CypherDriver) missingSchema(_ context.Context) ([]string, error) {
+	indexes, err := cd.readSchema(showIndexesCypher)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading the indexes: %w", err)
+	}
+	constraints, err := cd.readSchema(showConstraintsCypher)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading the constraints: %w", err)
+	}
+
+	var missing []string
+	for _, e := range expectedIndexes {
+		if !hasSchemaEntry(indexes, e) {
+			missing = append(missing, "index on "+e.String())
+		}
+	}
+	for _, e := range expectedConstraints {
+		if !hasSchemaEntry(constraints, e) {
+			missing = append(missing, "constraint on "+e.String())
+		}
+	}
+	return missing, nil
+}
+
+func (cd CypherDriver) readSchema(cypher string) ([]neoSchemaEntry, error) {
+	var results []neoSchemaEntry
+	query := &cmneo4j.Query{Cypher: cypher, Result: &results}
+	_, err := cd.driver.ReadMultiple([]*cmneo4j.Query{query}, nil)
+	if errors.Is(err, cmneo4j.ErrNoResultsFound) {
+		return nil, nil
+	}
+	return results, err
+}
+
+// hasSchemaEntry reports whether the schema holds an index or constraint on exactly the property of the label.
+func hasSchemaEntry(schema []neoSchemaEntry, e schemaEntry) bool {
+	for _, s := range schema {
+		if len(s.LabelsOrTypes) == 1 && s.LabelsOrTypes[0] == e.label && len(s.Properties) == 1 && s.Properties[0] == e.property {
+			return true
+		}
+	}
+	return false
+}
+
+// ProbeChecker fails when the probe query fails or takes longer than the threshold.
+func ProbeChecker(annDriver driver, threshold time.Duration) func() (string, error) {
+	return func() (string, error) {
+		pd, ok := annDriver.(probeDriver)
+		if !ok {
+			return "", errProbeNotSupported
+		}
+		latency, err := pd.probe(context.Background())
+		if errors.Is(err, errProbeNotSupported) {
+			return "The backend has no queries to probe", nil
+		}
+		if err != nil {
+			return "Error running the probe query", err
+		}
+		if latency > threshold {
+			return "The probe query is slow", fmt.Errorf("the probe query took %s, longer than %s", latency, threshold)
+		}
+		return fmt.Sprintf("The probe query took %s", latency), nil
+	}
+}
+
+// SchemaChecker fails when any of the indexes or constraints the annotations queries rely on is missing.
+func SchemaChecker(annDriver driver) func() (string, error) {
+	return func() (string, error) {
+		pd, ok := annDriver.(probeDriver)
+		if !ok {
+			return "", errProbeNotSupported
+		}
+		missing, err := pd.missingSchema(context.Background())
+		if errors.Is(err, errProbeNotSupported) {
+			return "The backend has no schema to check", nil
+		}
+		if err != nil {
+			return "Error reading the neo4j schema", err
+		}
+		if len(missing) > 0 {
+			return "The neo4j schema is incomplete", fmt.Errorf("missing %s", strings.Join(missing, ", "))
+		}
+		return "The expected indexes and constraints exist", nil
+	}
+}
+
+// CanaryChecker fails when the annotations of the canary content cannot be read, or there are none.
+func CanaryChecker(annDriver driver, contentUUID string) func() (string, error) {
+	return func() (string, error) {
+		res, err := annDriver.read(context.Background(), contentUUID, nil)
+		if err != nil {
+			return "Error reading the annotations of the canary content", err
+		}
+		if !res.found || len(res.annotations) == 0 {
+			return "The canary content has no annotations", fmt.Errorf("no annotations read for the canary content %s", contentUUID)
+		}
+		return fmt.Sprintf("Read %d annotations of the canary content", len(res.annotations)), nil
+	}
+}
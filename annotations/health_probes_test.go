@@ -0,0 +1,138 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProbeDriver struct {
+	mockDriver
+	latency  time.Duration
+	missing  []string
+	probeErr error
+}
+
+func (md mockProbeDriver) probe(context.Context) (time.Duration, error) {
+	return md.latency, md.probeErr
+}
+
+func (md mockProbeDriver) missingSchema(context.Context) ([]string, error) {
+	return md.missing, md.probeErr
+}
+
+func TestProbeChecker(t *testing.T) {
+	tests := map[string]struct {
+		driver      mockProbeDriver
+		expectedMsg string
+		expectErr   bool
+	}{
+		"Fast": {
+			driver:      mockProbeDriver{latency: 10 * time.Millisecond},
+			expectedMsg: "The probe query took 10ms",
+		},
+		"Slow": {
+			driver:      mockProbeDriver{latency: 3 * time.Second},
+			expectedMsg: "The probe query is slow",
+			expectErr:   true,
+		},
+		"Failing": {
+			driver:      mockProbeDriver{probeErr: errors.New("test error")},
+			expectedMsg: "Error running the probe query",
+			expectErr:   true,
+		},
+		"NotSupported": {
+			driver:      mockProbeDriver{probeErr: errProbeNotSupported},
+			expectedMsg: "The backend has no queries to probe",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg, err := ProbeChecker(test.driver, time.Second)()
+			assert.Equal(t, test.expectedMsg, msg)
+			assert.Equal(t, test.expectErr, err != nil, "error: %v", err)
+		})
+	}
+}
+
+func TestSchemaChecker(t *testing.T) {
+	msg, err := SchemaChecker(mockProbeDriver{})()
+	assert.NoError(t, err)
+	assert.Equal(t, "The expected indexes and constraints exist", msg)
+
+	_, err = SchemaChecker(mockProbeDriver{missing: []string{"index on :Content(uuid)", "constraint on :Content(uuid)"}})()
+	assert.EqualError(t, err, "missing index on :Content(uuid), constraint on :Content(uuid)")
+}
+
+func TestHasSchemaEntry(t *testing.T) {
+	schema := []neoSchemaEntry{
+		{LabelsOrTypes: []string{"Content"}, Properties: []string{"uuid"}},
+		{LabelsOrTypes: []string{"Concept"}, Properties: []string{"prefUUID", "uuid"}},
+	}
+
+	assert.True(t, hasSchemaEntry(schema, schemaEntry{label: "Content", property: "uuid"}))
+	assert.False(t, hasSchemaEntry(schema, schemaEntry{label: "Concept", property: "prefUUID"}), "a composite index")
+	assert.False(t, hasSchemaEntry(schema, schemaEntry{label: "Thing", property: "uuid"}))
+}
+
+func TestCanaryChecker(t *testing.T) {
+	tests := map[string]struct {
+		read      func(context.Context, string, []string) (Annotations, bool, error)
+		expectErr bool
+	}{
+		"WithAnnotations": {
+			read: func(context.Context, string, []string) (Annotations, bool, error) {
+				return Annotations{{ID: "id"}}, true, nil
+			},
+		},
+		"NotFound": {
+			read: func(context.Context, string, []string) (Annotations, bool, error) {
+				return nil, false, nil
+			},
+			expectErr: true,
+		},
+		"ReadError": {
+			read: func(context.Context, string, []string) (Annotations, bool, error) {
+				return nil, false, errors.New("test error")
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var readUUID string
+			d := mockDriver{readFunc: func(ctx context.Context, contentUUID string, bookmarks []string) (Annotations, bool, error) {
+				readUUID = contentUUID
+				return test.read(ctx, contentUUID, bookmarks)
+			}}
+
+			_, err := CanaryChecker(d, "canary")()
+			assert.Equal(t, test.expectErr, err != nil, "error: %v", err)
+			assert.Equal(t, "canary", readUUID)
+		})
+	}
+}
+
+func TestScheduleHealthChecks(t *testing.T) {
+	log := logger.NewUPPLogger("test-public-annotations-api", "PANIC")
+	ids := func(hctx *HandlerCtx, cfg HealthConfig) []string {
+		checks, _ := ScheduleHealthChecks(NewHealthScheduler(), hctx, cfg)
+		var ids []string
+		for _, c := range checks {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"neo4j-cluster-health"}, ids(NewHandlerCtx(mockDriver{}, &Settings{}, log), HealthConfig{}))
+	assert.Equal(t, []string{"neo4j-cluster-health", "canary-content"},
+		ids(NewHandlerCtx(mockDriver{}, &Settings{}, log), HealthConfig{CanaryContentUUID: "canary"}))
+	assert.Equal(t, []string{"neo4j-cluster-health", "neo4j-query-latency", "neo4j-schema"},
+		ids(NewHandlerCtx(mockProbeDriver{}, &Settings{}, log), HealthConfig{ProbeThreshold: time.Second}))
+}
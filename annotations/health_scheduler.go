@@ -0,0 +1,114 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+)
+
+// staleIntervals is the number of intervals after which the last result of a scheduled check is stale,
+// e.g. because the check hangs.
+const staleIntervals = 3
+
+var errNotCheckedYet = errors.New("the check has not run yet")
+
+// HealthScheduler runs the checks in the background at their intervals and serves their latest results,
+// so that the health and good-to-go endpoints do not hit the backend on every request.
+type HealthScheduler struct {
+	checks []*scheduledCheck
+	now    func() time.Time
+	wg     sync.WaitGroup
+}
+
+type scheduledCheck struct {
+	check    fthealth.Check
+	interval time.Duration
+
+	mu     sync.RWMutex
+	result checkResult
+}
+
+type checkResult struct {
+	output    string
+	err       error
+	checkedAt time.Time
+}
+
+func NewHealthScheduler() *HealthScheduler {
+	return &HealthScheduler{now: time.Now}
+}
+
+// Schedule returns a check serving the latest result of the given check, which is run every interval once the
+// scheduler is started. The returned check fails while the check has not run yet, and once its result is stale.
+func (hs *HealthScheduler) Schedule(check fthealth.Check, interval time.Duration) fthealth.Check {
+	sc := &scheduledCheck{check: check, interval: interval}
+	hs.checks = append(hs.checks, sc)
+
+	cached := check
+	cached.Checker = func() (string, error) {
+		return hs.latest(sc)
+	}
+	return cached
+}
+
+// Start runs every check right away and then at its interval, until the context is done.
+func (hs *HealthScheduler) Start(ctx context.Context) {
+	for _, sc := range hs.checks {
+		hs.wg.Add(1)
+		go func() {
+			defer hs.wg.Done()
+			hs.loop(ctx, sc)
+		}()
+	}
+}
+
+// Wait blocks until the checks started by Start stopped, once its context is done.
+// A check running when the context is done is waited for, so that it does not outlive what it checks, e.g. the neo4j driver.
+func (hs *HealthScheduler) Wait() {
+	hs.wg.Wait()
+}
+
+func (hs *HealthScheduler) loop(ctx context.Context, sc *scheduledCheck) {
+	hs.run(sc)
+	ticker := time.NewTicker(sc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			hs.run(sc)
+		}
+	}
+}
+
+func (hs *HealthScheduler) run(sc *scheduledCheck) {
+	output, err := sc.check.Checker()
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.result = checkResult{output: output, err: err, checkedAt: hs.now()}
+}
+
+// latest returns the latest result of the check, with its age.
+func (hs *HealthScheduler) latest(sc *scheduledCheck) (string, error) {
+	sc.mu.RLock()
+	res := sc.result
+	sc.mu.RUnlock()
+
+	if res.checkedAt.IsZero() {
+		return "", errNotCheckedYet
+	}
+	age := hs.now().Sub(res.checkedAt).Truncate(time.Second)
+	output := fmt.Sprintf("%s (checked %s ago)", res.output, age)
+	if age > staleIntervals*sc.interval {
+		return output, fmt.Errorf("the last result is stale, the check last ran %s ago", age)
+	}
+	if res.err != nil {
+		return output, fmt.Errorf("%w (checked %s ago)", res.err, age)
+	}
+	return output, nil
+}
@@ -0,0 +1,124 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthSchedulerServesLatestResult(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hs := NewHealthScheduler()
+	hs.now = func() time.Time { return now }
+
+	var checkErr error
+	var runs int
+	check := hs.Schedule(fthealth.Check{ID: "test", Checker: func() (string, error) {
+		runs++
+		return "test output", checkErr
+	}}, time.Minute)
+
+	_, err := check.Checker()
+	assert.ErrorIs(t, err, errNotCheckedYet)
+
+	hs.run(hs.checks[0])
+	now = now.Add(10 * time.Second)
+	output, err := check.Checker()
+	assert.NoError(t, err)
+	assert.Equal(t, "test output (checked 10s ago)", output)
+	_, _ = check.Checker()
+	assert.Equal(t, 1, runs, "the cached result is served without running the check")
+
+	checkErr = errors.New("test error")
+	hs.run(hs.checks[0])
+	_, err = check.Checker()
+	assert.ErrorIs(t, err, checkErr)
+	assert.EqualError(t, err, "test error (checked 0s ago)")
+
+	now = now.Add(4 * time.Minute)
+	_, err = check.Checker()
+	assert.EqualError(t, err, "the last result is stale, the check last ran 4m0s ago")
+}
+
+func TestHealthSchedulerStart(t *testing.T) {
+	hs := NewHealthScheduler()
+	var runs atomic.Int32
+	check := hs.Schedule(fthealth.Check{ID: "test", Checker: func() (string, error) {
+		runs.Add(1)
+		return "ok", nil
+	}}, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hs.Start(ctx)
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	_, err := check.Checker()
+	assert.NoError(t, err)
+}
+
+func TestHealthSchedulerWait(t *testing.T) {
+	hs := NewHealthScheduler()
+	running := make(chan struct{})
+	release := make(chan struct{})
+	var done atomic.Bool
+	hs.Schedule(fthealth.Check{ID: "test", Checker: func() (string, error) {
+		close(running)
+		<-release
+		done.Store(true)
+		return "ok", nil
+	}}, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hs.Start(ctx)
+	<-running
+	cancel()
+
+	waited := make(chan struct{})
+	go func() {
+		hs.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while a check was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-waited:
+		assert.True(t, done.Load())
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return once the check stopped")
+	}
+}
+
+func TestScheduleHealthChecksGoodToGo(t *testing.T) {
+	connErr := errors.New("test error")
+	hctx := NewHandlerCtx(mockDriver{checkConnectivityFunc: func() error { return connErr }}, &Settings{},
+		logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+	hs := NewHealthScheduler()
+
+	checks, goodToGo := ScheduleHealthChecks(hs, hctx, HealthConfig{ConnectivityInterval: time.Minute, QueryInterval: time.Minute})
+	assert.Len(t, checks, 1)
+
+	status := goodToGo()
+	assert.False(t, status.GoodToGo, "not checked yet")
+
+	hs.run(hs.checks[0])
+	status = goodToGo()
+	assert.False(t, status.GoodToGo)
+	assert.Equal(t, "test error (checked 0s ago)", status.Message)
+
+	connErr = nil
+	hs.run(hs.checks[0])
+	status = goodToGo()
+	assert.True(t, status.GoodToGo)
+}
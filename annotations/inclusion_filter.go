@@ -0,0 +1,73 @@
+package annotations
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// inclusionFilters creates the filters for the predicate and type query parameters present in params.
+func inclusionFilters(params url.Values) ([]annotationsFilter, error) {
+	var filters []annotationsFilter
+
+	if values, ok := params["predicate"]; ok {
+		var included []string
+		for _, v := range values {
+			predicate, ok := resolvePredicate(v)
+			if !ok {
+				return nil, &invalidQueryParamError{param: "predicate", value: v}
+			}
+			included = append(included, predicate)
+		}
+		filters = append(filters, &includePredicateFilter{predicates: included})
+	}
+
+	if values, ok := params["type"]; ok {
+		var included []string
+		for _, v := range values {
+			conceptType, ok := resolveType(v)
+			if !ok {
+				return nil, &invalidQueryParamError{param: "type", value: v}
+			}
+			included = append(included, conceptType)
+		}
+		filters = append(filters, &includeTypeFilter{types: included})
+	}
+
+	return filters, nil
+}
+
+// includePredicateFilter keeps the annotations with any of the predicates only.
+type includePredicateFilter struct {
+	predicates []string
+}
+
+func (f *includePredicateFilter) name() string {
+	return "includePredicate"
+}
+
+func (f *includePredicateFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
+	return chain.doNext(dropAnnotations(in, func(ann Annotation) bool {
+		return !slices.ContainsFunc(f.predicates, func(predicate string) bool {
+			return strings.EqualFold(predicate, ann.Predicate)
+		})
+	}))
+}
+
+// includeTypeFilter keeps the annotations of concepts with any of the types only.
+// Parent types match the annotations of their subtypes, e.g. Organisation matches Company and PublicCompany.
+type includeTypeFilter struct {
+	types []string
+}
+
+func (f *includeTypeFilter) name() string {
+	return "includeType"
+}
+
+func (f *includeTypeFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
+	return chain.doNext(dropAnnotations(in, func(ann Annotation) bool {
+		return !slices.ContainsFunc(f.types, func(t string) bool {
+			return hasType(ann.Types, t)
+		})
+	}))
+}
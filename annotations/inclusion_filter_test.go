@@ -0,0 +1,117 @@
+package annotations
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var publicCompanyAnnotation = Annotation{
+	ID:        "http://api.ft.com/things/a1b2c3d4-0000-4000-8000-000000000005",
+	Predicate: predicates["ABOUT"],
+	Types:     []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/concept/Concept", organisationTypeURI, companyTypeURI, "http://www.ft.com/ontology/company/PublicCompany"},
+}
+
+func TestInclusionFilters(t *testing.T) {
+	all := []Annotation{authorAnnotation, displayTagAnnotation, companyAnnotation, brandAnnotation, publicCompanyAnnotation}
+
+	tests := map[string]struct {
+		query    string
+		expected []Annotation
+	}{
+		"no inclusions": {
+			query:    "",
+			expected: all,
+		},
+		"predicates": {
+			query:    "predicate=hasAuthor&predicate=" + url.QueryEscape(predicates["HAS_DISPLAY_TAG"]),
+			expected: []Annotation{authorAnnotation, displayTagAnnotation},
+		},
+		"people": {
+			query:    "type=Person",
+			expected: []Annotation{authorAnnotation, displayTagAnnotation},
+		},
+		"parent type matches subtypes": {
+			query:    "type=organisation",
+			expected: []Annotation{companyAnnotation, publicCompanyAnnotation},
+		},
+		"type uri": {
+			query:    "type=" + url.QueryEscape("http://www.ft.com/ontology/company/PublicCompany"),
+			expected: []Annotation{publicCompanyAnnotation},
+		},
+		"companies mentioned": {
+			query:    "predicate=mentions&type=Company",
+			expected: []Annotation{companyAnnotation},
+		},
+		"nothing matches": {
+			query:    "predicate=about&type=Brand",
+			expected: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			params, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+			filters, err := inclusionFilters(params)
+			require.NoError(t, err)
+
+			chain := newAnnotationsFilterChain(filters...)
+			assert.Equal(t, tc.expected, chain.doNext(all))
+		})
+	}
+}
+
+func TestInclusionFiltersInvalidValues(t *testing.T) {
+	tests := map[string]string{
+		"predicate=hasEditor": "invalid predicate value: hasEditor",
+		"type=Person&type=":   "invalid type value: ",
+		"type=Persn":          "invalid type value: Persn",
+	}
+
+	for query, expectedError := range tests {
+		t.Run(query, func(t *testing.T) {
+			params, err := url.ParseQuery(query)
+			require.NoError(t, err)
+
+			_, err = inclusionFilters(params)
+			assert.EqualError(t, err, expectedError)
+		})
+	}
+}
+
+func TestInclusionFilterOfATypeOutsideTheHierarchy(t *testing.T) {
+	// a type of the ontology which is not part of the concept type hierarchy can be filtered by, whichever the backend
+	parent := conceptTypeParents["Genre"]
+	delete(conceptTypeParents, "Genre")
+	defer func() { conceptTypeParents["Genre"] = parent }()
+
+	genreURI, ok := typeURI("Genre")
+	require.True(t, ok)
+	genreAnnotation := Annotation{
+		ID:        "http://api.ft.com/things/a1b2c3d4-0000-4000-8000-000000000006",
+		Predicate: predicates["IS_CLASSIFIED_BY"],
+		Types:     []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/concept/Concept", genreURI},
+	}
+
+	params, err := url.ParseQuery("type=Genre")
+	require.NoError(t, err)
+	filters, err := inclusionFilters(params)
+	require.NoError(t, err)
+	assert.Equal(t, []Annotation{genreAnnotation}, newAnnotationsFilterChain(filters...).doNext([]Annotation{companyAnnotation, genreAnnotation}))
+}
+
+func TestInclusionFilterNames(t *testing.T) {
+	params, err := url.ParseQuery("predicate=about&type=Person")
+	require.NoError(t, err)
+	filters, err := inclusionFilters(params)
+	require.NoError(t, err)
+
+	// the names label the dropped annotations metric and the filter spans, so they differ from the other filters' ones
+	require.Len(t, filters, 2)
+	assert.Equal(t, "includePredicate", filters[0].name())
+	assert.Equal(t, "includeType", filters[1].name())
+	assert.NotEqual(t, NewAnnotationsPredicateFilter().name(), filters[0].name())
+}
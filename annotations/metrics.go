@@ -0,0 +1,84 @@
+package annotations
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsNamespace = "public_annotations_api"
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "neo4j_query_duration_seconds",
+		Help:      "Latency of the Neo4j queries, partitioned by query.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query"})
+
+	annotationsReturned = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "annotations_returned",
+		Help:      "Number of annotations returned per successful request.",
+		Buckets:   []float64{1, 2, 5, 10, 20, 50, 100, 200},
+	})
+
+	annotationsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "annotations_dropped_total",
+		Help:      "Number of annotations removed by each filter of the annotations filter chain.",
+	}, []string{"filter"})
+
+	lifecyclePrecedenceApplied = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "lifecycle_precedence_applied_total",
+		Help:      "Number of times the annotations of a lifecycle took precedence over the annotations of other lifecycles, partitioned by lifecycle.",
+	}, []string{"lifecycle"})
+
+	mappingFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "annotation_mapping_failures_total",
+		Help:      "Number of annotations read from Neo4j that could not be mapped to the response format, partitioned by reason.",
+	}, []string{"reason"})
+
+	readFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "neo4j_read_failures_total",
+		Help:      "Number of failed reads from Neo4j, partitioned by kind of failure.",
+	}, []string{"kind"})
+
+	bookmarkFallbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "bookmark_fallbacks_total",
+		Help:      "Number of best-effort reads which timed out waiting for their bookmark and were read without it.",
+	})
+
+	backendReads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "neo4j_backend_reads_total",
+		Help:      "Number of reads routed to each Neo4j backend, partitioned by backend and by whether the backend served the read, failed it over or failed it.",
+	}, []string{"backend", "result"})
+
+	backendCircuitOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "neo4j_backend_circuit_open",
+		Help:      "Whether the circuit of each Neo4j backend is open (1) or closed (0).",
+	}, []string{"backend"})
+
+	readsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "reads_in_flight",
+		Help:      "Number of annotations reads in flight, when the concurrent reads are limited.",
+	})
+
+	readQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "read_queue_depth",
+		Help:      "Number of annotations reads waiting for the reads in flight to complete.",
+	})
+
+	readsShed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reads_shed_total",
+		Help:      "Number of annotations reads rejected by the concurrency limit, partitioned by reason.",
+	}, []string{"reason"})
+)
@@ -0,0 +1,32 @@
+package annotations
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterChainCountsDroppedAnnotations(t *testing.T) {
+	lifecycleDropped := testutil.ToFloat64(annotationsDropped.WithLabelValues("lifecycle"))
+	dedupDropped := testutil.ToFloat64(annotationsDropped.WithLabelValues("dedup"))
+	pacApplied := testutil.ToFloat64(lifecyclePrecedenceApplied.WithLabelValues("pac"))
+
+	annotations := []Annotation{pacAnnotationA, pacAnnotationA, v1AnnotationA, v1AnnotationB, v2AnnotationA}
+	chain := newAnnotationsFilterChain(newLifecycleFilter())
+	filtered := chain.doNext(annotations)
+
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, lifecycleDropped+2, testutil.ToFloat64(annotationsDropped.WithLabelValues("lifecycle")))
+	assert.Equal(t, dedupDropped+1, testutil.ToFloat64(annotationsDropped.WithLabelValues("dedup")))
+	assert.Equal(t, pacApplied+1, testutil.ToFloat64(lifecyclePrecedenceApplied.WithLabelValues("pac")))
+}
+
+func TestMappingFailureReason(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &mappingError{reason: mappingFailurePredicate, err: errors.New("not a valid annotation type")})
+
+	assert.Equal(t, mappingFailurePredicate, mappingFailureReason(err))
+	assert.Equal(t, "unknown", mappingFailureReason(errors.New("test error")))
+}
@@ -19,11 +19,27 @@ type Annotation struct {
 	PrefLabel           string                   `json:"prefLabel,omitempty"`
 	GeonamesFeatureCode string                   `json:"geonamesFeatureCode,omitempty"`
 	IsDeprecated        bool                     `json:"isDeprecated,omitempty"`
+	Replaces            string                   `json:"replaces,omitempty"`
 	Publication         []string                 `json:"publication,omitempty"`
+	PublicationNames    []string                 `json:"publicationNames,omitempty"`
 	//used for filtering, e.g. pac not exposed
 	Lifecycle string `json:"-"`
 }
 
+// Warning describes an annotation which was left out of the response because it could not be mapped.
+type Warning struct {
+	ConceptID    string `json:"conceptId"`
+	Relationship string `json:"relationship"`
+	Reason       string `json:"reason"`
+	Message      string `json:"message"`
+}
+
+// AnnotationsWithWarnings is the response body returned when the warnings are requested.
+type AnnotationsWithWarnings struct {
+	Annotations Annotations `json:"annotations"`
+	Warnings    []Warning   `json:"warnings"`
+}
+
 var predicates = map[string]string{
 	"MENTIONS":                   "http://www.ft.com/ontology/annotation/mentions",
 	"MAJOR_MENTIONS":             "http://www.ft.com/ontology/annotation/majorMentions",
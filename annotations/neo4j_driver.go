@@ -0,0 +1,191 @@
+package annotations
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cmneo4j "github.com/Financial-Times/cm-neo4j-driver"
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+)
+
+// boundedReader is implemented by the neo4j readers able to bound the transactions of a read with a timeout.
+type boundedReader interface {
+	ReadMultipleWithin(timeout time.Duration, queries []*cmneo4j.Query, bookmarks []string) (string, error)
+}
+
+// Neo4jDriver runs the read queries of CypherDriver against Neo4j like cmneo4j.Driver does, and can also bound
+// the transactions of a read with a timeout which Neo4j enforces, so that a read taking too long is terminated
+// and releases its connection rather than being abandoned.
+type Neo4jDriver struct {
+	driver      neo4j.Driver
+	auth        neo4j.AuthToken
+	database    string
+	configurers []func(*neo4j.Config)
+}
+
+// NewNeo4jDriver connects to the neo4j instance or cluster at uri, logging the errors of the driver with log.
+func NewNeo4jDriver(uri string, log *logger.UPPLogger, opts ...func(*Neo4jDriver)) (*Neo4jDriver, error) {
+	nd := &Neo4jDriver{auth: neo4j.NoAuth()}
+	for _, opt := range opts {
+		opt(nd)
+	}
+
+	configurers := append([]func(*neo4j.Config){func(config *neo4j.Config) {
+		config.Log = neo4jLogger{log: log}
+	}}, nd.configurers...)
+	driver, err := neo4j.NewDriver(uri, nd.auth, configurers...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the neo4j driver: %w", err)
+	}
+	nd.driver = driver
+	return nd, nil
+}
+
+// WithNeo4jConfig changes the configuration of the underlying neo4j driver, e.g. its connection pool.
+func WithNeo4jConfig(configurer func(*neo4j.Config)) func(*Neo4jDriver) {
+	return func(nd *Neo4jDriver) {
+		nd.configurers = append(nd.configurers, configurer)
+	}
+}
+
+// WithNeo4jAuth authenticates to neo4j with the username and password, instead of connecting without authentication.
+func WithNeo4jAuth(username, password string) func(*Neo4jDriver) {
+	return func(nd *Neo4jDriver) {
+		nd.auth = neo4j.BasicAuth(username, password, "")
+	}
+}
+
+// WithNeo4jDatabase reads from the named database, instead of the default database of the server.
+func WithNeo4jDatabase(name string) func(*Neo4jDriver) {
+	return func(nd *Neo4jDriver) {
+		nd.database = name
+	}
+}
+
+// ReadMultiple runs the queries in a read transaction, which the driver retries on transient failures,
+// and returns the bookmark of the session. It fails with cmneo4j.ErrNoResultsFound if any of the queries returns no rows.
+func (nd *Neo4jDriver) ReadMultiple(queries []*cmneo4j.Query, bookmarks []string) (string, error) {
+	session := nd.newReadSession(bookmarks)
+	defer session.Close()
+
+	empty, err := session.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		return runQueries(tx, queries)
+	})
+	if err != nil {
+		return "", err
+	}
+	if empty.(bool) {
+		return session.LastBookmark(), cmneo4j.ErrNoResultsFound
+	}
+	return session.LastBookmark(), nil
+}
+
+// ReadMultipleWithin runs the queries like ReadMultiple, in a transaction Neo4j terminates once it runs for longer than timeout.
+// The transaction is not retried, so that the read is bounded by the timeout once Neo4j started it.
+func (nd *Neo4jDriver) ReadMultipleWithin(timeout time.Duration, queries []*cmneo4j.Query, bookmarks []string) (string, error) {
+	session := nd.newReadSession(bookmarks)
+	defer session.Close()
+
+	tx, err := session.BeginTransaction(neo4j.WithTxTimeout(timeout))
+	if err != nil {
+		return "", err
+	}
+	defer tx.Close()
+
+	empty, err := runQueries(tx, queries)
+	if err != nil {
+		return "", err
+	}
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
+	if empty {
+		return session.LastBookmark(), cmneo4j.ErrNoResultsFound
+	}
+	return session.LastBookmark(), nil
+}
+
+func (nd *Neo4jDriver) VerifyConnectivity() error {
+	return nd.driver.VerifyConnectivity()
+}
+
+func (nd *Neo4jDriver) Close() error {
+	return nd.driver.Close()
+}
+
+func (nd *Neo4jDriver) newReadSession(bookmarks []string) neo4j.Session {
+	return nd.driver.NewSession(nd.readSessionConfig(bookmarks))
+}
+
+func (nd *Neo4jDriver) readSessionConfig(bookmarks []string) neo4j.SessionConfig {
+	return neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, Bookmarks: bookmarks, DatabaseName: nd.database}
+}
+
+// runQueries runs the queries in the transaction, decoding their rows into their results.
+// It reports whether any of the queries returned no rows.
+func runQueries(tx neo4j.Transaction, queries []*cmneo4j.Query) (bool, error) {
+	empty := false
+	for _, q := range queries {
+		result, err := tx.Run(q.Cypher, q.Params)
+		if err != nil {
+			return false, err
+		}
+		records, err := result.Collect()
+		if err != nil {
+			return false, err
+		}
+		if len(records) == 0 {
+			empty = true
+			continue
+		}
+		if q.Result == nil {
+			continue
+		}
+		if err = decodeRecords(records, q.Result); err != nil {
+			return false, fmt.Errorf("failed decoding the rows of the query: %w", err)
+		}
+	}
+	return empty, nil
+}
+
+// decodeRecords decodes the records into result, a pointer to a slice, matching the keys of the records
+// with the JSON names of the fields, ignoring case.
+func decodeRecords(records []*neo4j.Record, result interface{}) error {
+	rows := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		row := make(map[string]interface{}, len(record.Keys))
+		for i, key := range record.Keys {
+			row[key] = record.Values[i]
+		}
+		rows = append(rows, row)
+	}
+
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// neo4jLogger logs the messages of the neo4j driver.
+type neo4jLogger struct {
+	log *logger.UPPLogger
+}
+
+func (l neo4jLogger) Error(name string, id string, err error) {
+	l.log.WithError(err).WithField("component", name).WithField("id", id).Error("neo4j driver error")
+}
+
+func (l neo4jLogger) Warnf(name string, id string, msg string, args ...interface{}) {
+	l.log.WithField("component", name).WithField("id", id).Warnf(msg, args...)
+}
+
+func (l neo4jLogger) Infof(name string, id string, msg string, args ...interface{}) {
+	l.log.WithField("component", name).WithField("id", id).Infof(msg, args...)
+}
+
+func (l neo4jLogger) Debugf(name string, id string, msg string, args ...interface{}) {
+	l.log.WithField("component", name).WithField("id", id).Debugf(msg, args...)
+}
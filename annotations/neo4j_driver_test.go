@@ -0,0 +1,55 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRecords(t *testing.T) {
+	keys := []string{"id", "predicate", "types", "lifecycle", "publication", "naicsRank", "isDeprecated", "factsetID"}
+	records := []*neo4j.Record{
+		{Keys: keys, Values: []interface{}{"a", "ABOUT", []interface{}{"Thing", "Concept"}, "v2", []interface{}{"pub"}, int64(1), true, []interface{}{"F1"}}},
+		{Keys: keys, Values: []interface{}{"b", "MENTIONS", nil, "pac", nil, nil, false, nil}},
+	}
+
+	var rows []neoAnnotation
+	require.NoError(t, decodeRecords(records, &rows))
+	assert.Equal(t, []neoAnnotation{
+		{ID: "a", Predicate: "ABOUT", Types: []string{"Thing", "Concept"}, Lifecycle: "v2", Publication: []string{"pub"}, NAICSRank: 1, IsDeprecated: true, FactsetIDs: []string{"F1"}},
+		{ID: "b", Predicate: "MENTIONS", Lifecycle: "pac"},
+	}, rows)
+}
+
+func TestNewNeo4jDriverOptions(t *testing.T) {
+	log := logger.NewUPPLogger("test", "ERROR")
+
+	// creating the driver does not connect to neo4j
+	nd, err := NewNeo4jDriver("bolt://localhost:7687", log)
+	require.NoError(t, err)
+	defer nd.Close()
+	assert.Equal(t, neo4j.NoAuth(), nd.auth)
+	assert.Equal(t, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, Bookmarks: []string{"FB:in"}}, nd.readSessionConfig([]string{"FB:in"}))
+
+	var poolSize int
+	nd, err = NewNeo4jDriver("bolt://localhost:7687", log,
+		WithNeo4jAuth("reader", "secret"),
+		WithNeo4jDatabase("annotations"),
+		WithNeo4jConfig(func(config *neo4j.Config) {
+			config.MaxConnectionPoolSize = 7
+		}),
+		WithNeo4jConfig(func(config *neo4j.Config) {
+			poolSize = config.MaxConnectionPoolSize
+		}))
+	require.NoError(t, err)
+	defer nd.Close()
+	assert.Equal(t, neo4j.BasicAuth("reader", "secret", ""), nd.auth)
+	assert.Equal(t, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, DatabaseName: "annotations"}, nd.readSessionConfig(nil))
+	assert.Equal(t, 7, poolSize, "the configurers are applied in order")
+
+	_, err = NewNeo4jDriver("http://localhost:7474", log)
+	assert.ErrorContains(t, err, "could not create the neo4j driver")
+}
@@ -1,18 +1,117 @@
 package annotations
 
-import "slices"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
 
 const (
 	ftPink = "88fdde6c-2aa4-4f78-af02-9f680097cfd6"
 )
 
+// Publication is a publication the content can belong to.
+type Publication struct {
+	UUID string `yaml:"uuid" json:"uuid"`
+	// ShortName is accepted by the publication query parameter in place of the uuid, ignoring case.
+	ShortName   string `yaml:"shortName" json:"shortName"`
+	DisplayName string `yaml:"displayName" json:"displayName"`
+}
+
+// PublicationRegistry lists the known publications.
+type PublicationRegistry struct {
+	Publications []Publication `yaml:"publications" json:"publications"`
+	// Default is the short name or uuid of the publication the annotations without publication belong to.
+	Default string `yaml:"default" json:"default"`
+}
+
+// DefaultPublicationRegistry is used unless another registry is configured.
+var DefaultPublicationRegistry = PublicationRegistry{
+	Publications: []Publication{
+		{UUID: ftPink, ShortName: "ft", DisplayName: "Financial Times"},
+		{UUID: "8e6c705e-1132-42a2-8db0-c295e29e8658", ShortName: "sv", DisplayName: "Sustainable Views"},
+	},
+	Default: "ft",
+}
+
+// LoadPublicationRegistry reads the publication registry from a YAML or JSON file like
+//
+//	publications:
+//	  - uuid: 88fdde6c-2aa4-4f78-af02-9f680097cfd6
+//	    shortName: ft
+//	    displayName: Financial Times
+//	default: ft
+func LoadPublicationRegistry(path string) (*PublicationRegistry, error) {
+	var registry PublicationRegistry
+	if err := decodeConfigFile(path, "publication registry", &registry); err != nil {
+		return nil, err
+	}
+
+	if err := registry.validate(); err != nil {
+		return nil, fmt.Errorf("invalid publication registry file %s: %w", path, err)
+	}
+	return &registry, nil
+}
+
+func (r *PublicationRegistry) validate() error {
+	if len(r.Publications) == 0 {
+		return errors.New("no publications defined")
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range r.Publications {
+		if p.UUID == "" || p.ShortName == "" {
+			return fmt.Errorf("publication %d has an empty uuid or short name", i+1)
+		}
+		for _, key := range []string{p.UUID, strings.ToLower(p.ShortName)} {
+			if seen[key] {
+				return fmt.Errorf("publication %s is defined more than once", key)
+			}
+			seen[key] = true
+		}
+	}
+
+	if _, ok := r.lookup(r.Default); !ok {
+		return fmt.Errorf("unknown default publication %q", r.Default)
+	}
+	return nil
+}
+
+// lookup finds a publication by its uuid or short name.
+func (r *PublicationRegistry) lookup(value string) (Publication, bool) {
+	for _, p := range r.Publications {
+		if p.UUID == value || strings.EqualFold(p.ShortName, value) {
+			return p, true
+		}
+	}
+	return Publication{}, false
+}
+
+// resolveParams maps the values of a publication query parameter to the uuids of the publications.
+func (r *PublicationRegistry) resolveParams(param string, publicationParams []string) ([]string, error) {
+	var uuids []string
+	for _, pp := range publicationParams {
+		p, ok := r.lookup(pp)
+		if !ok {
+			return nil, &invalidQueryParamError{param: param, value: pp}
+		}
+		if !slices.Contains(uuids, p.UUID) {
+			uuids = append(uuids, p.UUID)
+		}
+	}
+
+	return uuids, nil
+}
+
 type publicationFilter struct {
+	registry        *PublicationRegistry
 	publication     []string
 	showPublication bool
 }
 
 func newPublicationFilter(opts ...func(*publicationFilter)) *publicationFilter {
-	pf := publicationFilter{}
+	pf := publicationFilter{registry: &DefaultPublicationRegistry}
 	for _, opt := range opts {
 		opt(&pf)
 	}
@@ -20,6 +119,7 @@ func newPublicationFilter(opts ...func(*publicationFilter)) *publicationFilter {
 	return &pf
 }
 
+// withPublication keeps the annotations of the publications with the given uuids only.
 func withPublication(publication []string, showPublication bool) func(filter *publicationFilter) {
 	return func(f *publicationFilter) {
 		f.showPublication = showPublication
@@ -27,6 +127,19 @@ func withPublication(publication []string, showPublication bool) func(filter *pu
 	}
 }
 
+// withPublicationRegistry replaces the default publication registry of the filter. A nil registry keeps the default.
+func withPublicationRegistry(registry *PublicationRegistry) func(*publicationFilter) {
+	return func(f *publicationFilter) {
+		if registry != nil {
+			f.registry = registry
+		}
+	}
+}
+
+func (f *publicationFilter) name() string {
+	return "publication"
+}
+
 func (f *publicationFilter) filter(in []Annotation, chain *annotationsFilterChain) []Annotation {
 	return chain.doNext(f.filterByPublication(in))
 }
@@ -35,17 +148,12 @@ func (f *publicationFilter) filterByPublication(annotations []Annotation) []Anno
 	var filtered []Annotation
 
 	if len(f.publication) > 0 {
+		defaultPublication, _ := f.registry.lookup(f.registry.Default)
 		for _, annotation := range annotations {
-			for _, pub := range f.publication {
-				if slices.Contains(annotation.Publication, pub) {
-					filtered = append(filtered, annotation)
-				}
-
-				if pub == ftPink {
-					if annotation.Publication == nil {
-						filtered = append(filtered, annotation)
-					}
-				}
+			if slices.ContainsFunc(f.publication, func(pub string) bool {
+				return slices.Contains(annotation.Publication, pub) || (pub == defaultPublication.UUID && annotation.Publication == nil)
+			}) {
+				filtered = append(filtered, annotation)
 			}
 		}
 	} else {
@@ -57,10 +165,20 @@ func (f *publicationFilter) filterByPublication(annotations []Annotation) []Anno
 	return filtered
 }
 
+// applyShowPublicationFilter hides the publications of the annotations, or adds their display names if they are shown.
 func (f *publicationFilter) applyShowPublicationFilter(filtered []Annotation) {
-	if !f.showPublication {
-		for i := range filtered {
+	for i := range filtered {
+		if !f.showPublication {
 			filtered[i].Publication = nil
+			filtered[i].PublicationNames = nil
+			continue
+		}
+		var names []string
+		for _, pub := range filtered[i].Publication {
+			if p, ok := f.registry.lookup(pub); ok && p.DisplayName != "" {
+				names = append(names, p.DisplayName)
+			}
 		}
+		filtered[i].PublicationNames = names
 	}
 }
@@ -1,6 +1,8 @@
 package annotations
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,6 +37,19 @@ var annotationD = Annotation{
 	Publication: nil,
 }
 
+// withPublicationNames returns the annotation as shown with showPublication, with the display names of its publications.
+func withPublicationNames(annotation Annotation, names ...string) Annotation {
+	annotation.PublicationNames = names
+	return annotation
+}
+
+// the annotations A, B and C as shown with showPublication
+var (
+	shownAnnotationA = withPublicationNames(annotationA, "Sustainable Views")
+	shownAnnotationB = withPublicationNames(annotationB, "Financial Times")
+	shownAnnotationC = withPublicationNames(annotationC, "Sustainable Views")
+)
+
 func TestPublicationFiltering(t *testing.T) {
 	tests := map[string]struct {
 		publication []string
@@ -42,19 +57,19 @@ func TestPublicationFiltering(t *testing.T) {
 	}{
 		"Filter by FT Pink publication": {
 			publication: []string{ftPink},
-			expected:    []Annotation{annotationB, annotationD},
+			expected:    []Annotation{shownAnnotationB, annotationD},
 		},
 		"Filter by SV publication": {
 			publication: []string{sv},
-			expected:    []Annotation{annotationA, annotationC},
+			expected:    []Annotation{shownAnnotationA, shownAnnotationC},
 		},
 		"Filter by SV and FT Pink publication": {
 			publication: []string{sv, ftPink},
-			expected:    []Annotation{annotationA, annotationB, annotationC, annotationD},
+			expected:    []Annotation{shownAnnotationA, shownAnnotationB, shownAnnotationC, annotationD},
 		},
 		"No publication filter applied": {
 			publication: []string{},
-			expected:    []Annotation{annotationA, annotationB, annotationC, annotationD},
+			expected:    []Annotation{shownAnnotationA, shownAnnotationB, shownAnnotationC, annotationD},
 		},
 		"Unknown publication filter applied": {
 			publication: []string{"unknown"},
@@ -74,3 +89,83 @@ func TestPublicationFiltering(t *testing.T) {
 		})
 	}
 }
+
+func TestPublicationFilteringWithConfiguredDefault(t *testing.T) {
+	registry := &PublicationRegistry{
+		Publications: []Publication{{UUID: ftPink, ShortName: "ft"}, {UUID: sv, ShortName: "sv"}},
+		Default:      "sv",
+	}
+	f := newPublicationFilter(withPublicationRegistry(registry), withPublication([]string{sv}, false))
+	filtered := newAnnotationsFilterChain(f).doNext([]Annotation{annotationA, annotationB, annotationD})
+
+	assert.Equal(t, []string{annotationA.ID, annotationD.ID}, []string{filtered[0].ID, filtered[1].ID})
+	assert.Len(t, filtered, 2)
+	assert.Nil(t, filtered[0].Publication, "publications are hidden")
+}
+
+func TestPublicationRegistryResolveParams(t *testing.T) {
+	uuids, err := DefaultPublicationRegistry.resolveParams("publication", []string{"FT", sv, "sv", ftPink})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ftPink, sv}, uuids)
+
+	_, err = DefaultPublicationRegistry.resolveParams("publication", []string{"ft", "unknown"})
+	assert.EqualError(t, err, "invalid publication value: unknown")
+}
+
+func TestLoadPublicationRegistry(t *testing.T) {
+	tests := map[string]struct {
+		config        string
+		expected      *PublicationRegistry
+		expectedError string
+	}{
+		"valid registry": {
+			config: "publications:\n  - uuid: " + sv + "\n    shortName: sv\n    displayName: Sustainable Views\ndefault: sv\n",
+			expected: &PublicationRegistry{
+				Publications: []Publication{{UUID: sv, ShortName: "sv", DisplayName: "Sustainable Views"}},
+				Default:      "sv",
+			},
+		},
+		"default by uuid": {
+			config: `{"publications": [{"uuid": "` + sv + `", "shortName": "sv"}], "default": "` + sv + `"}`,
+			expected: &PublicationRegistry{
+				Publications: []Publication{{UUID: sv, ShortName: "sv"}},
+				Default:      sv,
+			},
+		},
+		"no publications": {
+			config:        "default: ft\n",
+			expectedError: "no publications defined",
+		},
+		"publication without short name": {
+			config:        "publications:\n  - uuid: " + sv + "\ndefault: " + sv + "\n",
+			expectedError: "publication 1 has an empty uuid or short name",
+		},
+		"short name defined twice": {
+			config:        "publications:\n  - uuid: " + sv + "\n    shortName: sv\n  - uuid: " + st + "\n    shortName: SV\ndefault: sv\n",
+			expectedError: "publication sv is defined more than once",
+		},
+		"unknown default": {
+			config:        "publications:\n  - uuid: " + sv + "\n    shortName: sv\ndefault: ft\n",
+			expectedError: `unknown default publication "ft"`,
+		},
+		"unknown field": {
+			config:        "publications: []\ndefaults: ft\n",
+			expectedError: "field defaults not found",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "publications.yml")
+			assert.NoError(t, os.WriteFile(path, []byte(tc.config), 0o600))
+
+			registry, err := LoadPublicationRegistry(path)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, registry)
+		})
+	}
+}
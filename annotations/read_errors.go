@@ -0,0 +1,86 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+)
+
+// Kinds of failures reading the annotations from Neo4j.
+const (
+	// readFailureInvalidBookmark is a bookmark Neo4j rejected, which fails however many times the read is retried
+	readFailureInvalidBookmark = "invalid_bookmark"
+	// readFailureBookmarkTimeout is a replica which did not catch up with the bookmark in time
+	readFailureBookmarkTimeout = "bookmark_timeout"
+	// readFailureTransient is a cluster or connectivity failure which may succeed when retried
+	readFailureTransient = "transient"
+	// readFailureQuery is a failing query, which is a bug of the service or the database rather than an outage
+	readFailureQuery = "query"
+	// readFailureUnknown is any other failure
+	readFailureUnknown = "unknown"
+)
+
+// readError is returned by CypherDriver when reading from Neo4j fails and carries the kind of the failure.
+type readError struct {
+	kind string
+	err  error
+}
+
+func (e *readError) Error() string {
+	return e.err.Error()
+}
+
+func (e *readError) Unwrap() error {
+	return e.err
+}
+
+// newReadError classifies the failure of a Neo4j read and counts it.
+func newReadError(err error) *readError {
+	kind := classifyNeo4jError(err)
+	readFailures.WithLabelValues(kind).Inc()
+	return &readError{kind: kind, err: err}
+}
+
+func readFailureKind(err error) string {
+	var rErr *readError
+	if errors.As(err, &rErr) {
+		return rErr.kind
+	}
+	return readFailureUnknown
+}
+
+func classifyNeo4jError(err error) string {
+	var limitErr *neo4j.TransactionExecutionLimit
+	if errors.As(err, &limitErr) {
+		// the read was retried until giving up, so it is classified by its last failure
+		if len(limitErr.Errors) == 0 {
+			return readFailureTransient
+		}
+		if kind := classifyNeo4jError(limitErr.Errors[len(limitErr.Errors)-1]); kind != readFailureUnknown {
+			return kind
+		}
+		return readFailureTransient
+	}
+
+	var neoErr *neo4j.Neo4jError
+	if errors.As(err, &neoErr) {
+		switch {
+		case strings.HasPrefix(neoErr.Code, "Neo.ClientError.Transaction.InvalidBookmark"):
+			return readFailureInvalidBookmark
+		case neoErr.Code == "Neo.TransientError.Transaction.BookmarkTimeout":
+			return readFailureBookmarkTimeout
+		case strings.HasPrefix(neoErr.Code, "Neo.TransientError."):
+			return readFailureTransient
+		case strings.HasPrefix(neoErr.Code, "Neo.ClientError."), strings.HasPrefix(neoErr.Code, "Neo.DatabaseError."):
+			return readFailureQuery
+		}
+	}
+
+	var connErr *neo4j.ConnectivityError
+	if errors.As(err, &connErr) || errors.Is(err, context.DeadlineExceeded) {
+		return readFailureTransient
+	}
+	return readFailureUnknown
+}
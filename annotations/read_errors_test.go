@@ -0,0 +1,78 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyNeo4jError(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected string
+	}{
+		"invalid bookmark": {
+			err:      fmt.Errorf("query failed: %w", &neo4j.Neo4jError{Code: "Neo.ClientError.Transaction.InvalidBookmark"}),
+			expected: readFailureInvalidBookmark,
+		},
+		"mixed bookmarks": {
+			err:      &neo4j.Neo4jError{Code: "Neo.ClientError.Transaction.InvalidBookmarkMixture"},
+			expected: readFailureInvalidBookmark,
+		},
+		"bookmark timeout": {
+			err:      &neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.BookmarkTimeout"},
+			expected: readFailureBookmarkTimeout,
+		},
+		"bookmark timeout after retries": {
+			err: &neo4j.TransactionExecutionLimit{Errors: []error{
+				&neo4j.Neo4jError{Code: "Neo.TransientError.Cluster.NotALeader"},
+				&neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.BookmarkTimeout"},
+			}},
+			expected: readFailureBookmarkTimeout,
+		},
+		"retries exhausted": {
+			err:      &neo4j.TransactionExecutionLimit{Errors: []error{errors.New("TEST failing to READ")}},
+			expected: readFailureTransient,
+		},
+		"transient cluster error": {
+			err:      &neo4j.Neo4jError{Code: "Neo.TransientError.General.DatabaseUnavailable"},
+			expected: readFailureTransient,
+		},
+		"connectivity error": {
+			err:      fmt.Errorf("query failed: %w", &neo4j.ConnectivityError{}),
+			expected: readFailureTransient,
+		},
+		"deadline exceeded": {
+			err:      fmt.Errorf("query failed: %w", context.DeadlineExceeded),
+			expected: readFailureTransient,
+		},
+		"syntax error": {
+			err:      &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError"},
+			expected: readFailureQuery,
+		},
+		"database error": {
+			err:      &neo4j.Neo4jError{Code: "Neo.DatabaseError.General.UnknownError"},
+			expected: readFailureQuery,
+		},
+		"other error": {
+			err:      errors.New("TEST failing to READ"),
+			expected: readFailureUnknown,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, classifyNeo4jError(tc.err))
+		})
+	}
+}
+
+func TestReadFailureKind(t *testing.T) {
+	err := fmt.Errorf("failed: %w", newReadError(&neo4j.Neo4jError{Code: "Neo.ClientError.Transaction.InvalidBookmark"}))
+	assert.Equal(t, readFailureInvalidBookmark, readFailureKind(err))
+	assert.Equal(t, readFailureUnknown, readFailureKind(errors.New("TEST failing to READ")))
+}
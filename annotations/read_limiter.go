@@ -0,0 +1,168 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reasons of shedding a read, as counted by the readsShed metric.
+const (
+	// shedQueueFull is a read which found the limit reached and the wait queue full
+	shedQueueFull = "queue_full"
+	// shedQueueTimeout is a read which waited in the queue for longer than the maximum wait
+	shedQueueTimeout = "queue_timeout"
+	// shedClientLimit is a read of a client which already has its maximum number of reads in flight or queued
+	shedClientLimit = "client_limit"
+)
+
+var (
+	// errOverloaded is returned by ReadLimiter when the limit of reads in flight is reached and the read could not be queued
+	errOverloaded = errors.New("too many reads in flight")
+	// errClientLimit is returned by ReadLimiter when a client has too many reads in flight or queued
+	errClientLimit = errors.New("too many reads in flight for the client")
+	// errReadCancelled is returned by ReadLimiter when the request is cancelled while its read is queued,
+	// e.g. because the client went away. The read is not shed, as there is nobody left to retry it.
+	errReadCancelled = errors.New("read cancelled while queued")
+)
+
+// ReadLimiter caps the number of reads in flight. The reads beyond the limit wait in a bounded queue for a read
+// to complete, and are shed once the queue is full or they waited for too long. A new read does not overtake
+// the queued ones, which are allowed roughly in their order of arrival: the runtime does not guarantee in which
+// order the reads blocked on the limit are woken up.
+// It can also cap the reads of each client, identified by a request header.
+type ReadLimiter struct {
+	slots   chan struct{}
+	queue   chan struct{}
+	maxWait time.Duration
+	// clientHeader identifies the client of a request for the per client limit
+	clientHeader string
+	perClient    int
+
+	mu      sync.Mutex
+	clients map[string]int
+}
+
+// NewReadLimiter limits the reads in flight to maxInFlight, without queueing the reads beyond the limit.
+func NewReadLimiter(maxInFlight int, opts ...func(*ReadLimiter)) *ReadLimiter {
+	rl := &ReadLimiter{slots: make(chan struct{}, maxInFlight), queue: make(chan struct{}), clients: map[string]int{}}
+	for _, opt := range opts {
+		opt(rl)
+	}
+	return rl
+}
+
+// WithReadQueue lets up to size reads wait for up to maxWait when the limit of reads in flight is reached.
+func WithReadQueue(size int, maxWait time.Duration) func(*ReadLimiter) {
+	return func(rl *ReadLimiter) {
+		rl.queue = make(chan struct{}, size)
+		rl.maxWait = maxWait
+	}
+}
+
+// WithClientLimit limits the reads in flight or queued of each client, identified by the value of the header,
+// to maxPerClient. The requests without the header are only subject to the overall limit.
+func WithClientLimit(header string, maxPerClient int) func(*ReadLimiter) {
+	return func(rl *ReadLimiter) {
+		rl.clientHeader = header
+		rl.perClient = maxPerClient
+	}
+}
+
+// acquire waits for the read of the request to be allowed, and returns the function releasing it once the read completed.
+// A nil ReadLimiter allows all the reads.
+func (rl *ReadLimiter) acquire(r *http.Request) (func(), error) {
+	if rl == nil {
+		return func() {}, nil
+	}
+
+	client := r.Header.Get(rl.clientHeader)
+	if !rl.acquireClient(client) {
+		readsShed.WithLabelValues(shedClientLimit).Inc()
+		return nil, errClientLimit
+	}
+
+	if err := rl.acquireSlot(r.Context()); err != nil {
+		rl.releaseClient(client)
+		return nil, err
+	}
+	readsInFlight.Inc()
+
+	return func() {
+		readsInFlight.Dec()
+		<-rl.slots
+		rl.releaseClient(client)
+	}, nil
+}
+
+func (rl *ReadLimiter) acquireSlot(ctx context.Context) error {
+	// a free slot is only taken right away while no read is queued for it
+	if len(rl.queue) == 0 {
+		select {
+		case rl.slots <- struct{}{}:
+			return nil
+		default:
+		}
+	}
+
+	select {
+	case rl.queue <- struct{}{}:
+	default:
+		readsShed.WithLabelValues(shedQueueFull).Inc()
+		return errOverloaded
+	}
+	readQueueDepth.Inc()
+	defer func() {
+		<-rl.queue
+		readQueueDepth.Dec()
+	}()
+
+	timer := time.NewTimer(rl.maxWait)
+	defer timer.Stop()
+	select {
+	case rl.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		readsShed.WithLabelValues(shedQueueTimeout).Inc()
+		return errOverloaded
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", errReadCancelled, ctx.Err())
+	}
+}
+
+func (rl *ReadLimiter) acquireClient(client string) bool {
+	if rl.perClient <= 0 || client == "" {
+		return true
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.clients[client] >= rl.perClient {
+		return false
+	}
+	rl.clients[client]++
+	return true
+}
+
+func (rl *ReadLimiter) releaseClient(client string) {
+	if rl.perClient <= 0 || client == "" {
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.clients[client]--; rl.clients[client] <= 0 {
+		delete(rl.clients, client)
+	}
+}
+
+// retryAfter is the value of the Retry-After header of the shed reads, in whole seconds: the maximum wait in the queue,
+// and at least a second.
+func (rl *ReadLimiter) retryAfter() string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(rl.maxWait.Seconds()))))
+}
@@ -0,0 +1,211 @@
+package annotations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClientRequest(client string) *http.Request {
+	req := newRequest(fmt.Sprintf("/content/%s/annotations", knownUUID))
+	if client != "" {
+		req.Header.Set("X-Api-Key", client)
+	}
+	return req
+}
+
+func TestReadLimiter(t *testing.T) {
+	rl := NewReadLimiter(1, WithReadQueue(1, time.Second))
+
+	release, err := rl.acquire(newClientRequest(""))
+	require.NoError(t, err)
+
+	// the second read waits in the queue until the first one completes
+	acquired := make(chan func())
+	go func() {
+		r, err := rl.acquire(newClientRequest(""))
+		assert.NoError(t, err)
+		acquired <- r
+	}()
+	assert.Eventually(t, func() bool { return len(rl.queue) == 1 }, time.Second, time.Millisecond)
+
+	_, err = rl.acquire(newClientRequest(""))
+	assert.ErrorIs(t, err, errOverloaded, "the queue is full")
+
+	release()
+	select {
+	case r := <-acquired:
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("the queued read was not allowed once the first one completed")
+	}
+	assert.Empty(t, rl.slots)
+	assert.Empty(t, rl.queue)
+}
+
+func TestReadLimiterQueueTimeout(t *testing.T) {
+	rl := NewReadLimiter(1, WithReadQueue(1, 10*time.Millisecond))
+	release, err := rl.acquire(newClientRequest(""))
+	require.NoError(t, err)
+	defer release()
+
+	_, err = rl.acquire(newClientRequest(""))
+	assert.ErrorIs(t, err, errOverloaded)
+	assert.Empty(t, rl.queue)
+}
+
+func TestReadLimiterWithoutQueue(t *testing.T) {
+	rl := NewReadLimiter(1)
+	release, err := rl.acquire(newClientRequest(""))
+	require.NoError(t, err)
+	defer release()
+
+	_, err = rl.acquire(newClientRequest(""))
+	assert.ErrorIs(t, err, errOverloaded)
+}
+
+func TestReadLimiterClientLimit(t *testing.T) {
+	rl := NewReadLimiter(10, WithClientLimit("X-Api-Key", 1))
+
+	release, err := rl.acquire(newClientRequest("client-a"))
+	require.NoError(t, err)
+
+	_, err = rl.acquire(newClientRequest("client-a"))
+	assert.ErrorIs(t, err, errClientLimit)
+
+	releaseB, err := rl.acquire(newClientRequest("client-b"))
+	assert.NoError(t, err, "other clients are not limited")
+	releaseB()
+	releaseAnonymous, err := rl.acquire(newClientRequest(""))
+	assert.NoError(t, err, "the requests without client are not limited")
+	releaseAnonymous()
+
+	release()
+	release, err = rl.acquire(newClientRequest("client-a"))
+	assert.NoError(t, err, "the client read completed")
+	release()
+	assert.Empty(t, rl.clients)
+}
+
+func TestReadLimiterNil(t *testing.T) {
+	var rl *ReadLimiter
+	release, err := rl.acquire(newClientRequest(""))
+	assert.NoError(t, err)
+	release()
+}
+
+func TestReadLimiterCancelledWhileQueued(t *testing.T) {
+	rl := NewReadLimiter(1, WithReadQueue(1, time.Second))
+	release, err := rl.acquire(newClientRequest(""))
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = rl.acquire(newClientRequest("").WithContext(ctx))
+	assert.ErrorIs(t, err, errReadCancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, errOverloaded)
+	assert.Empty(t, rl.queue)
+}
+
+func TestReadLimiterDoesNotOvertakeTheQueue(t *testing.T) {
+	rl := NewReadLimiter(1, WithReadQueue(1, 10*time.Millisecond))
+	// a read is queued for the slot which was just freed
+	rl.queue <- struct{}{}
+
+	_, err := rl.acquire(newClientRequest(""))
+	assert.ErrorIs(t, err, errOverloaded, "the new read does not take the free slot ahead of the queued one")
+	assert.Empty(t, rl.slots)
+
+	<-rl.queue
+	release, err := rl.acquire(newClientRequest(""))
+	require.NoError(t, err)
+	release()
+}
+
+func TestReadLimiterRetryAfter(t *testing.T) {
+	assert.Equal(t, "1", NewReadLimiter(1).retryAfter())
+	assert.Equal(t, "1", NewReadLimiter(1, WithReadQueue(1, 200*time.Millisecond)).retryAfter())
+	assert.Equal(t, "3", NewReadLimiter(1, WithReadQueue(1, 2500*time.Millisecond)).retryAfter())
+}
+
+func TestGetAnnotationsShedReads(t *testing.T) {
+	annotationsDriver := mockDriver{
+		readFunc: func(context.Context, string, []string) (Annotations, bool, error) {
+			return Annotations{pacAnnotationA}, true, nil
+		},
+	}
+
+	tests := map[string]struct {
+		client             string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"overloaded": {
+			client:             "client-b",
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       errorBody(http.StatusServiceUnavailable, codeOverloaded, knownUUID, "Too many annotations reads in flight, retry later"),
+		},
+		"client limit": {
+			client:             "client-a",
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedBody:       errorBody(http.StatusTooManyRequests, codeTooManyRequests, knownUUID, "Too many annotations reads in flight for the client, retry later"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+			hctx.ReadLimiter = NewReadLimiter(1, WithReadQueue(0, 2*time.Second), WithClientLimit("X-Api-Key", 1))
+			// client-a has the only read in flight
+			release, err := hctx.ReadLimiter.acquire(newClientRequest("client-a"))
+			require.NoError(t, err)
+			defer release()
+
+			rec := httptest.NewRecorder()
+			r := mux.NewRouter()
+			r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+			r.ServeHTTP(rec, newClientRequest(tc.client))
+
+			assert.Equal(t, tc.expectedStatusCode, rec.Code)
+			assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+			assert.Equal(t, problemContentType, rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestGetAnnotationsCancelledWhileQueued(t *testing.T) {
+	var reads int
+	annotationsDriver := mockDriver{
+		readFunc: func(context.Context, string, []string) (Annotations, bool, error) {
+			reads++
+			return Annotations{pacAnnotationA}, true, nil
+		},
+	}
+	hctx := NewHandlerCtx(annotationsDriver, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+	hctx.ReadLimiter = NewReadLimiter(1, WithReadQueue(1, 2*time.Second))
+	release, err := hctx.ReadLimiter.acquire(newClientRequest(""))
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	r := mux.NewRouter()
+	r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+	r.ServeHTTP(rec, newClientRequest("").WithContext(ctx))
+
+	assert.Empty(t, rec.Body.String())
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+	assert.Zero(t, reads)
+}
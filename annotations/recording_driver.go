@@ -0,0 +1,188 @@
+package annotations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+	"github.com/Financial-Times/go-logger/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recording holds the raw rows read for a piece of content, as written by RecordingDriver.
+type recording struct {
+	ContentUUID string          `json:"contentUUID"`
+	Bookmarks   []string        `json:"bookmarks,omitempty"`
+	RecordedAt  time.Time       `json:"recordedAt"`
+	Rows        []neoAnnotation `json:"rows"`
+}
+
+func recordingPath(dir, contentUUID string) string {
+	return filepath.Join(dir, filepath.Base(contentUUID)+".json")
+}
+
+// RecordingDriver decorates a driver, writing the raw rows read for every piece of content to a directory,
+// one file per content uuid. The recordings can be served by ReplayDriver to reproduce a response offline.
+type RecordingDriver struct {
+	driver driver
+	dir    string
+	log    *logger.UPPLogger
+}
+
+// NewRecordingDriver decorates d with a RecordingDriver, which can be probed like d only if d can be probed,
+// so that the health checks probing the driver are only scheduled for the backends able to serve them.
+func NewRecordingDriver(d driver, dir string, log *logger.UPPLogger) driver {
+	rd := &RecordingDriver{driver: d, dir: dir, log: log}
+	if pd, ok := d.(probeDriver); ok {
+		return &probedRecordingDriver{RecordingDriver: rd, probed: pd}
+	}
+	return rd
+}
+
+func (rd *RecordingDriver) checkConnectivity() error {
+	return rd.driver.checkConnectivity()
+}
+
+// read reads the annotations with the decorated driver and records the rows they were mapped from.
+// The reads which fail for other reasons than unmappable annotations are not recorded.
+// Failing to write a recording is logged and does not fail the read.
+func (rd *RecordingDriver) read(ctx context.Context, contentUUID string, bookmarks []string) (readResult, error) {
+	res, err := rd.driver.read(ctx, contentUUID, bookmarks)
+
+	var unmappedErr *unmappedAnnotationsError
+	if err == nil || errors.As(err, &unmappedErr) {
+		rec := recording{ContentUUID: contentUUID, Bookmarks: bookmarks, RecordedAt: time.Now().UTC(), Rows: res.rows}
+		if recErr := rd.write(rec); recErr != nil {
+			rd.log.WithError(recErr).WithUUID(contentUUID).Error("failed recording annotations")
+		}
+	}
+
+	return res, err
+}
+
+// write replaces the recording of the content through a rename, so that concurrent reads of the same content
+// never leave a partially written file behind.
+func (rd *RecordingDriver) write(rec recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(rd.dir, ".recording-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), recordingPath(rd.dir, rec.ContentUUID))
+}
+
+// diagnose delegates to the decorated driver, if it supports diagnostics.
+func (rd *RecordingDriver) diagnose(ctx context.Context, contentUUID string) (ContentDiagnostics, bool, error) {
+	d, ok := rd.driver.(diagnosticsDriver)
+	if !ok {
+		return ContentDiagnostics{}, false, errDiagnosticsNotSupported
+	}
+	return d.diagnose(ctx, contentUUID)
+}
+
+// successors delegates to the decorated driver, if it supports resolving successors.
+func (rd *RecordingDriver) successors(ctx context.Context, conceptUUIDs []string) (map[string]Annotation, error) {
+	return readSuccessors(ctx, rd.driver, conceptUUIDs)
+}
+
+// healthChecks delegates to the decorated driver, if it reads from several backends.
+func (rd *RecordingDriver) healthChecks() []fthealth.Check {
+	d, ok := rd.driver.(backendHealthDriver)
+	if !ok {
+		return nil
+	}
+	return d.healthChecks()
+}
+
+// probedRecordingDriver is a RecordingDriver whose decorated driver can be probed.
+type probedRecordingDriver struct {
+	*RecordingDriver
+	probed probeDriver
+}
+
+func (rd *probedRecordingDriver) probe(ctx context.Context) (time.Duration, error) {
+	return rd.probed.probe(ctx)
+}
+
+func (rd *probedRecordingDriver) missingSchema(ctx context.Context) ([]string, error) {
+	return rd.probed.missingSchema(ctx)
+}
+
+// ReplayDriver serves the annotations from the recordings written by RecordingDriver.
+// The recordings are read on every request, so new recordings are served without a restart.
+type ReplayDriver struct {
+	dir           string
+	baseURL       string
+	strictMapping bool
+}
+
+func NewReplayDriver(dir string, baseURL string, opts ...func(*ReplayDriver)) *ReplayDriver {
+	rd := &ReplayDriver{dir: dir, baseURL: baseURL}
+	for _, opt := range opts {
+		opt(rd)
+	}
+
+	return rd
+}
+
+// WithReplayStrictMapping makes the replay driver return an error when any of the annotations cannot be mapped to the response format.
+func WithReplayStrictMapping(strict bool) func(*ReplayDriver) {
+	return func(rd *ReplayDriver) {
+		rd.strictMapping = strict
+	}
+}
+
+func (rd *ReplayDriver) checkConnectivity() error {
+	info, err := os.Stat(rd.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", rd.dir)
+	}
+	return nil
+}
+
+// read maps the recorded rows of a piece of content. The bookmarks are ignored.
+// Content without a recording is reported as not found.
+func (rd *ReplayDriver) read(ctx context.Context, contentUUID string, _ []string) (readResult, error) {
+	_, span := startSpan(ctx, "ReplayDriver.read", trace.WithAttributes(attribute.String(contentUUIDAttribute, contentUUID)))
+	defer span.End()
+
+	data, err := os.ReadFile(recordingPath(rd.dir, contentUUID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return readResult{annotations: Annotations{}}, nil
+	}
+	if err != nil {
+		return readResult{}, fmt.Errorf("failed reading the recording of contentUUID %s: %w", contentUUID, err)
+	}
+
+	var rec recording
+	if err = json.Unmarshal(data, &rec); err != nil {
+		return readResult{}, fmt.Errorf("failed decoding the recording of contentUUID %s: %w", contentUUID, err)
+	}
+	span.SetAttributes(attribute.Int(rowsCountAttribute, len(rec.Rows)))
+
+	return mapResults(contentUUID, rec.Rows, rd.baseURL, rd.strictMapping)
+}
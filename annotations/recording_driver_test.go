@@ -0,0 +1,117 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var recordedRows = []neoAnnotation{
+	{
+		ID:          ConceptA,
+		Predicate:   "ABOUT",
+		Types:       []string{"Thing", "Concept", "Topic"},
+		PrefLabel:   "Topic A",
+		Lifecycle:   pacLifecycle,
+		Publication: []string{sv},
+	},
+	{
+		ID:        ConceptB,
+		Predicate: "NOT_AN_ANNOTATION",
+		Types:     []string{"Thing", "Concept", "Topic"},
+		PrefLabel: "Topic B",
+		Lifecycle: pacLifecycle,
+	},
+}
+
+func rowsDriver(rows []neoAnnotation, strict bool) mockDriver {
+	return mockDriver{
+		readResultFunc: func(_ context.Context, contentUUID string, _ []string) (readResult, error) {
+			return mapResults(contentUUID, rows, "http://api.ft.com", strict)
+		},
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecordingDriver(rowsDriver(recordedRows, false), dir, logger.NewUPPLogger("test-service", "PANIC"))
+
+	recorded, err := recorder.read(context.Background(), knownUUID, nil)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, knownUUID+".json"))
+
+	replayed, err := NewReplayDriver(dir, "http://api.ft.com").read(context.Background(), knownUUID, nil)
+	require.NoError(t, err)
+	assert.True(t, replayed.found)
+	assert.Equal(t, recorded.annotations, replayed.annotations)
+	assert.Equal(t, recorded.warnings, replayed.warnings)
+	assert.Len(t, replayed.warnings, 1)
+}
+
+func TestRecordUnmappedAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecordingDriver(rowsDriver(recordedRows, true), dir, logger.NewUPPLogger("test-service", "PANIC"))
+
+	_, err := recorder.read(context.Background(), knownUUID, nil)
+	var unmappedErr *unmappedAnnotationsError
+	require.ErrorAs(t, err, &unmappedErr)
+
+	_, err = NewReplayDriver(dir, "http://api.ft.com", WithReplayStrictMapping(true)).read(context.Background(), knownUUID, nil)
+	assert.ErrorAs(t, err, &unmappedErr)
+}
+
+func TestRecordReadError(t *testing.T) {
+	dir := t.TempDir()
+	failing := mockDriver{
+		readFunc: func(context.Context, string, []string) (Annotations, bool, error) {
+			return nil, false, errors.New("TEST failing to READ")
+		},
+	}
+	recorder := NewRecordingDriver(failing, dir, logger.NewUPPLogger("test-service", "PANIC"))
+
+	_, err := recorder.read(context.Background(), knownUUID, nil)
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, knownUUID+".json"))
+}
+
+func TestReplayWithoutRecording(t *testing.T) {
+	res, err := NewReplayDriver(t.TempDir(), "http://api.ft.com").read(context.Background(), knownUUID, nil)
+	assert.NoError(t, err)
+	assert.False(t, res.found)
+	assert.Equal(t, Annotations{}, res.annotations)
+}
+
+func TestReplayCorruptRecording(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, knownUUID+".json"), []byte("{"), 0o600))
+
+	_, err := NewReplayDriver(dir, "http://api.ft.com").read(context.Background(), knownUUID, nil)
+	assert.Error(t, err)
+}
+
+func TestRecordingDriverProbes(t *testing.T) {
+	log := logger.NewUPPLogger("test-service", "PANIC")
+
+	recorder := NewRecordingDriver(mockDriver{}, t.TempDir(), log)
+	_, ok := recorder.(probeDriver)
+	assert.False(t, ok, "the recording driver cannot be probed if the decorated driver cannot")
+	assert.Empty(t, queryChecks(NewHandlerCtx(recorder, &Settings{}, log), HealthConfig{}))
+
+	recorder = NewRecordingDriver(mockProbeDriver{latency: time.Second, missing: []string{"index"}}, t.TempDir(), log)
+	probed, ok := recorder.(probeDriver)
+	require.True(t, ok, "the recording driver can be probed like the decorated driver")
+	latency, err := probed.probe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, latency)
+	missing, err := probed.missingSchema(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"index"}, missing)
+	assert.Len(t, queryChecks(NewHandlerCtx(recorder, &Settings{}, log), HealthConfig{}), 2)
+}
@@ -0,0 +1,43 @@
+package annotations
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type reloadResponse struct {
+	Changes []string `json:"changes"`
+}
+
+// PostReload reloads the settings with load, like on SIGHUP, and responds with what changed.
+// The requests must be authenticated with the token as a bearer token; an empty token rejects all of them.
+func PostReload(hctx *HandlerCtx, token string, load func() (*Settings, error)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeErrorResponse(hctx, w, newErrorResponse(http.StatusUnauthorized, codeUnauthorized, "Unauthorized"))
+			return
+		}
+
+		// the error is logged by ReloadSettings and left out of the response, as it can reveal the paths and contents of the files
+		changes, err := hctx.ReloadSettings(load)
+		if err != nil {
+			writeErrorResponse(hctx, w, newErrorResponse(http.StatusInternalServerError, codeInternalError, "Failed reloading settings, the current ones are kept"))
+			return
+		}
+
+		if changes == nil {
+			changes = []string{}
+		}
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(reloadResponse{Changes: changes}); err != nil {
+			hctx.Log.WithError(err).Error("failed writing reload response")
+		}
+	}
+}
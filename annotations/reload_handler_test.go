@@ -0,0 +1,96 @@
+package annotations
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostReload(t *testing.T) {
+	tests := []struct {
+		name                string
+		token               string
+		authorization       string
+		load                func() (*Settings, error)
+		expectedStatusCode  int
+		expectedBody        string
+		expectedCacheHeader string
+	}{
+		{
+			name:          "reloaded",
+			token:         "secret",
+			authorization: "Bearer secret",
+			load: func() (*Settings, error) {
+				return &Settings{CacheControlHeader: "max-age=120, public"}, nil
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedBody:        `{"changes":["cache control header changed from \"max-age=60, public\" to \"max-age=120, public\""]}`,
+			expectedCacheHeader: "max-age=120, public",
+		},
+		{
+			name:          "nothing changed",
+			token:         "secret",
+			authorization: "Bearer secret",
+			load: func() (*Settings, error) {
+				return &Settings{CacheControlHeader: "max-age=60, public"}, nil
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedBody:        `{"changes":[]}`,
+			expectedCacheHeader: "max-age=60, public",
+		},
+		{
+			name:          "failed loading",
+			token:         "secret",
+			authorization: "Bearer secret",
+			load: func() (*Settings, error) {
+				return nil, errors.New("TEST failing to LOAD")
+			},
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedBody:        `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal-error","detail":"Failed reloading settings, the current ones are kept"}`,
+			expectedCacheHeader: "max-age=60, public",
+		},
+		{
+			name:                "wrong token",
+			token:               "secret",
+			authorization:       "Bearer guess",
+			expectedStatusCode:  http.StatusUnauthorized,
+			expectedBody:        `{"type":"about:blank","title":"Unauthorized","status":401,"code":"unauthorized","detail":"Unauthorized"}`,
+			expectedCacheHeader: "max-age=60, public",
+		},
+		{
+			name:                "missing token",
+			token:               "secret",
+			expectedStatusCode:  http.StatusUnauthorized,
+			expectedBody:        `{"type":"about:blank","title":"Unauthorized","status":401,"code":"unauthorized","detail":"Unauthorized"}`,
+			expectedCacheHeader: "max-age=60, public",
+		},
+		{
+			name:                "no token configured",
+			authorization:       "Bearer ",
+			expectedStatusCode:  http.StatusUnauthorized,
+			expectedBody:        `{"type":"about:blank","title":"Unauthorized","status":401,"code":"unauthorized","detail":"Unauthorized"}`,
+			expectedCacheHeader: "max-age=60, public",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hctx := NewHandlerCtx(mockDriver{}, &Settings{CacheControlHeader: "max-age=60, public"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+			req := httptest.NewRequest(http.MethodPost, "/__reload", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			PostReload(hctx, test.token, test.load)(rec, req)
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.JSONEq(t, test.expectedBody, rec.Body.String())
+			assert.Equal(t, test.expectedCacheHeader, hctx.Settings().CacheControlHeader)
+		})
+	}
+}
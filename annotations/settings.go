@@ -0,0 +1,129 @@
+package annotations
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Settings are the settings of the handlers and the filters which can be reloaded without a restart.
+type Settings struct {
+	CacheControlHeader string
+	// PredicateRules replace the DefaultPredicateRules of the predicate filter, if set
+	PredicateRules PredicateRules
+	// LifecyclePolicy replaces the DefaultLifecyclePolicy, if set
+	LifecyclePolicy *LifecyclePolicy
+	// PublicationRegistry replaces the DefaultPublicationRegistry, if set
+	PublicationRegistry *PublicationRegistry
+}
+
+// RuntimeConfig is the format of the runtime configuration file, holding the reloadable settings
+// which are not defined in a configuration file of their own.
+type RuntimeConfig struct {
+	CacheDuration string `yaml:"cacheDuration"`
+}
+
+// LoadRuntimeConfig reads the runtime configuration from a YAML or JSON file.
+func LoadRuntimeConfig(path string) (RuntimeConfig, error) {
+	var cfg RuntimeConfig
+	if err := decodeConfigFile(path, "runtime config", &cfg); err != nil {
+		return RuntimeConfig{}, err
+	}
+	return cfg, nil
+}
+
+// CacheControlHeader builds the Cache-Control header of the successful responses from a duration like 2h45m.
+func CacheControlHeader(cacheDuration string) (string, error) {
+	duration, err := time.ParseDuration(cacheDuration)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse cache duration string: %w", err)
+	}
+	return fmt.Sprintf("max-age=%.0f, public", duration.Seconds()), nil
+}
+
+func (s *Settings) predicateRules() PredicateRules {
+	if s.PredicateRules != nil {
+		return s.PredicateRules
+	}
+	return DefaultPredicateRules
+}
+
+func (s *Settings) lifecyclePolicy() *LifecyclePolicy {
+	if s.LifecyclePolicy != nil {
+		return s.LifecyclePolicy
+	}
+	return &DefaultLifecyclePolicy
+}
+
+func (s *Settings) publicationRegistry() *PublicationRegistry {
+	if s.PublicationRegistry != nil {
+		return s.PublicationRegistry
+	}
+	return &DefaultPublicationRegistry
+}
+
+// diffSettings describes the differences between the effective values of two settings.
+func diffSettings(prev, next *Settings) []string {
+	var changes []string
+	if prev.CacheControlHeader != next.CacheControlHeader {
+		changes = append(changes, fmt.Sprintf("cache control header changed from %q to %q", prev.CacheControlHeader, next.CacheControlHeader))
+	}
+	if prevRules, nextRules := prev.predicateRules(), next.predicateRules(); !reflect.DeepEqual(prevRules, nextRules) {
+		changes = append(changes, fmt.Sprintf("predicate rules changed from %v to %v", prevRules, nextRules))
+	}
+	prevPolicy, nextPolicy := prev.lifecyclePolicy(), next.lifecyclePolicy()
+	if !reflect.DeepEqual(prevPolicy.Lifecycles, nextPolicy.Lifecycles) {
+		changes = append(changes, fmt.Sprintf("lifecycles changed from %v to %v", prevPolicy.Lifecycles, nextPolicy.Lifecycles))
+	}
+	if !reflect.DeepEqual(prevPolicy.Precedence, nextPolicy.Precedence) {
+		changes = append(changes, fmt.Sprintf("lifecycle precedence changed from %v to %v", prevPolicy.Precedence, nextPolicy.Precedence))
+	}
+	prevRegistry, nextRegistry := prev.publicationRegistry(), next.publicationRegistry()
+	if !reflect.DeepEqual(prevRegistry.Publications, nextRegistry.Publications) {
+		changes = append(changes, fmt.Sprintf("publications changed from %v to %v", prevRegistry.Publications, nextRegistry.Publications))
+	}
+	if prevRegistry.Default != nextRegistry.Default {
+		changes = append(changes, fmt.Sprintf("default publication changed from %s to %s", prevRegistry.Default, nextRegistry.Default))
+	}
+	return changes
+}
+
+// Settings returns the current settings. They must not be modified, but replaced with UpdateSettings.
+func (hctx *HandlerCtx) Settings() *Settings {
+	if s := hctx.settings.Load(); s != nil {
+		return s
+	}
+	return &Settings{}
+}
+
+// UpdateSettings atomically replaces the settings used by the handlers and returns what changed.
+// Requests being served keep using the settings they started with.
+func (hctx *HandlerCtx) UpdateSettings(s *Settings) []string {
+	prev := hctx.settings.Swap(s)
+	if prev == nil {
+		prev = &Settings{}
+	}
+	return diffSettings(prev, s)
+}
+
+// ReloadSettings loads the settings with load and swaps them in, logging what changed.
+// The current settings are kept if load fails. Concurrent reloads are serialised.
+func (hctx *HandlerCtx) ReloadSettings(load func() (*Settings, error)) ([]string, error) {
+	hctx.reloadMu.Lock()
+	defer hctx.reloadMu.Unlock()
+
+	s, err := load()
+	if err != nil {
+		hctx.Log.WithError(err).Error("failed reloading settings, keeping the current ones")
+		return nil, err
+	}
+
+	changes := hctx.UpdateSettings(s)
+	if len(changes) == 0 {
+		hctx.Log.Info("settings reloaded, nothing changed")
+	}
+	for _, change := range changes {
+		hctx.Log.WithField("change", change).Info("settings reloaded")
+	}
+	return changes, nil
+}
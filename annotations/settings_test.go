@@ -0,0 +1,78 @@
+package annotations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheControlHeader(t *testing.T) {
+	header, err := CacheControlHeader("2h45m")
+	assert.NoError(t, err)
+	assert.Equal(t, "max-age=9900, public", header)
+
+	_, err = CacheControlHeader("forever")
+	assert.ErrorContains(t, err, "failed to parse cache duration string")
+}
+
+func TestLoadRuntimeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.yml")
+	require.NoError(t, os.WriteFile(path, []byte("cacheDuration: 10m\n"), 0o600))
+
+	cfg, err := LoadRuntimeConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, RuntimeConfig{CacheDuration: "10m"}, cfg)
+
+	require.NoError(t, os.WriteFile(path, []byte("cacheTTL: 10m\n"), 0o600))
+	_, err = LoadRuntimeConfig(path)
+	assert.ErrorContains(t, err, "failed parsing runtime config file")
+}
+
+func TestDiffSettings(t *testing.T) {
+	policy := &LifecyclePolicy{
+		Lifecycles: DefaultLifecyclePolicy.Lifecycles,
+		Precedence: []PrecedenceRule{{Lifecycle: "pac", Suppresses: []string{"v1"}}},
+	}
+
+	assert.Empty(t, diffSettings(&Settings{}, &Settings{PredicateRules: DefaultPredicateRules, LifecyclePolicy: &DefaultLifecyclePolicy}),
+		"the defaults and the unset settings are the same")
+
+	changes := diffSettings(
+		&Settings{CacheControlHeader: "max-age=60, public"},
+		&Settings{CacheControlHeader: "max-age=120, public", PredicateRules: PredicateRules{{"MENTIONS", "ABOUT"}}, LifecyclePolicy: policy},
+	)
+	require.Len(t, changes, 3)
+	assert.Contains(t, changes[0], "cache control header")
+	assert.Contains(t, changes[1], "predicate rules")
+	assert.Contains(t, changes[2], "lifecycle precedence")
+}
+
+func TestReloadSettings(t *testing.T) {
+	hctx := NewHandlerCtx(mockDriver{}, &Settings{CacheControlHeader: "max-age=60, public"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+
+	changes, err := hctx.ReloadSettings(func() (*Settings, error) {
+		return &Settings{CacheControlHeader: "max-age=120, public"}, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "max-age=120, public", hctx.Settings().CacheControlHeader)
+
+	changes, err = hctx.ReloadSettings(func() (*Settings, error) {
+		return nil, errors.New("TEST failing to LOAD")
+	})
+	assert.Error(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, "max-age=120, public", hctx.Settings().CacheControlHeader, "the current settings are kept")
+}
+
+func TestUpdateSettingsWithoutSettings(t *testing.T) {
+	hctx := NewHandlerCtx(mockDriver{}, nil, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+
+	assert.Equal(t, &Settings{}, hctx.Settings())
+	assert.Len(t, hctx.UpdateSettings(&Settings{CacheControlHeader: "max-age=60, public"}), 1)
+}
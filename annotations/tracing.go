@@ -0,0 +1,30 @@
+package annotations
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Financial-Times/public-annotations-api/v3/annotations"
+
+// Span attribute keys
+const (
+	contentUUIDAttribute      = "content.uuid"
+	transactionIDAttribute    = "transaction.id"
+	lifecycleParamsAttribute  = "annotations.lifecycle"
+	annotationsCountAttribute = "annotations.count"
+	annotationsInAttribute    = "annotations.in"
+	queryNameAttribute        = "neo4j.query"
+	rowsCountAttribute        = "neo4j.rows"
+	bookmarksCountAttribute   = "neo4j.bookmarks"
+	conceptsCountAttribute    = "concepts.count"
+	backendAttribute          = "neo4j.backend"
+)
+
+// startSpan starts a span using the globally registered tracer provider.
+// The tracer is looked up on every call, so that a provider registered after the package is loaded is always used.
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
@@ -0,0 +1,79 @@
+package annotations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	prevProvider := otel.GetTracerProvider()
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return exporter
+}
+
+func TestGetAnnotationsTracing(t *testing.T) {
+	exporter := setupInMemoryTracing(t)
+
+	hctx := NewHandlerCtx(mockDriver{
+		readFunc: func(context.Context, string, []string) (Annotations, bool, error) {
+			return []Annotation{pacAnnotationA, pacAnnotationB, v1AnnotationA}, true, nil
+		},
+	}, &Settings{CacheControlHeader: "test-header"}, logger.NewUPPLogger("test-public-annotations-api", "PANIC"))
+
+	req := newRequest(fmt.Sprintf("/content/%s/annotations?lifecycle=pac", knownUUID))
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-Id", "tid_test")
+
+	rec := httptest.NewRecorder()
+	r := mux.NewRouter()
+	r.HandleFunc("/content/{uuid}/annotations", GetAnnotations(hctx)).Methods("GET")
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub)
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+
+	root, ok := byName["GetAnnotations"]
+	require.True(t, ok, "handler span not found")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", root.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", root.Parent.SpanID().String())
+	assert.Contains(t, root.Attributes, attribute.String(contentUUIDAttribute, knownUUID))
+	assert.Contains(t, root.Attributes, attribute.String(transactionIDAttribute, "tid_test"))
+	assert.Contains(t, root.Attributes, attribute.StringSlice(lifecycleParamsAttribute, []string{"pac"}))
+	assert.Contains(t, root.Attributes, attribute.Int(annotationsCountAttribute, 2))
+
+	for _, name := range []string{"lifecycle", "predicate", "publication", "dedup"} {
+		s, ok := byName["annotationsFilter."+name]
+		require.True(t, ok, "span for %s filter not found", name)
+		assert.Equal(t, root.SpanContext.SpanID(), s.Parent.SpanID(), "span for %s filter has wrong parent", name)
+	}
+	lifecycleSpan := byName["annotationsFilter.lifecycle"]
+	assert.Contains(t, lifecycleSpan.Attributes, attribute.Int(annotationsInAttribute, 3))
+	assert.Contains(t, lifecycleSpan.Attributes, attribute.Int(annotationsCountAttribute, 2))
+}
@@ -1,25 +1,42 @@
 package main
 
 import (
+	"context"
+	"crypto/x509"
+	"errors"
 	"net/http"
+	"net/http/pprof"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"fmt"
-	"strconv"
+	"io"
+	"sync/atomic"
 	"time"
 
-	cmneo4j "github.com/Financial-Times/cm-neo4j-driver"
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 	"github.com/Financial-Times/http-handlers-go/v2/httphandlers"
 
 	apiEndpoint "github.com/Financial-Times/api-endpoint"
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/public-annotations-api/v3/annotations"
+	"github.com/Financial-Times/service-status-go/gtg"
 	status "github.com/Financial-Times/service-status-go/httphandlers"
 	"github.com/gorilla/mux"
 	cli "github.com/jawher/mow.cli"
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rcrowley/go-metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
 const (
@@ -27,19 +44,125 @@ const (
 	appDescription = "A public RESTful API for accessing Annotations in neo4j"
 )
 
+// Backends the annotations can be read from.
+const (
+	backendNeo4j    = "neo4j"
+	backendFixtures = "fixtures"
+	backendReplay   = "replay"
+)
+
+type serverConfig struct {
+	neoURL                string
+	neoCAFile             string
+	neoUsername           string
+	neoPassword           string
+	neoPasswordFile       string
+	neoDatabase           string
+	neoMaxPoolSize        int
+	neoAcquisitionTimeout string
+	neoCircuitFailures    int
+	neoCircuitOpenFor     string
+	port                  string
+	cacheDuration         string
+	apiURL                string
+	apiYml                string
+	strictMapping         bool
+	bookmarkReadTimeout   string
+	backend               string
+	fixturesDir           string
+	recordingsDir         string
+	predicateRules        string
+	lifecyclePolicy       string
+	publicationRegistry   string
+	runtimeConfig         string
+	adminToken            string
+	probeThreshold        string
+	canaryContentUUID     string
+	connectivityInterval  string
+	queryCheckInterval    string
+	shutdownDelay         string
+	shutdownGracePeriod   string
+	adminPort             string
+	maxInFlightReads      int
+	readQueueSize         int
+	readQueueTimeout      string
+	maxClientReads        int
+	clientHeader          string
+}
+
 func main() {
 	app := cli.App(appName, appDescription)
 	neoURL := app.String(cli.StringOpt{
 		Name:   "neo-url",
 		Value:  "bolt://localhost:7687",
-		Desc:   "neo4j endpoint URL",
+		Desc:   "neo4j endpoint URL, or comma-separated URLs of the neo4j clusters in order of preference, which the reads fail over between",
 		EnvVar: "NEO_URL"})
+	neoCAFile := app.String(cli.StringOpt{
+		Name:   "neo-ca-file",
+		Value:  "",
+		Desc:   "PEM bundle of the certificate authorities trusted for TLS connections to neo4j, which are enabled by the neo4j+s:// or bolt+s:// schemes. The system ones are trusted if empty.",
+		EnvVar: "NEO_CA_FILE",
+	})
+	neoUsername := app.String(cli.StringOpt{
+		Name:   "neo-username",
+		Value:  "",
+		Desc:   "Username authenticating to neo4j. The connections are not authenticated if empty.",
+		EnvVar: "NEO_USERNAME",
+	})
+	neoPassword := app.String(cli.StringOpt{
+		Name:   "neo-password",
+		Value:  "",
+		Desc:   "Password of the neo4j username",
+		EnvVar: "NEO_PASSWORD",
+	})
+	neoPasswordFile := app.String(cli.StringOpt{
+		Name:   "neo-password-file",
+		Value:  "",
+		Desc:   "File holding the password of the neo4j username, e.g. a mounted secret, instead of neo-password",
+		EnvVar: "NEO_PASSWORD_FILE",
+	})
+	neoDatabase := app.String(cli.StringOpt{
+		Name:   "neo-database",
+		Value:  "",
+		Desc:   "Name of the neo4j database the annotations are read from. The default database of the server is read from if empty.",
+		EnvVar: "NEO_DATABASE",
+	})
+	neoMaxPoolSize := app.Int(cli.IntOpt{
+		Name:   "neo-max-pool-size",
+		Value:  100,
+		Desc:   "Maximum number of connections to each neo4j instance",
+		EnvVar: "NEO_MAX_POOL_SIZE",
+	})
+	neoAcquisitionTimeout := app.String(cli.StringOpt{
+		Name:   "neo-acquisition-timeout",
+		Value:  "1m",
+		Desc:   "Maximum duration of waiting for a connection to neo4j from the pool, e.g. 5s",
+		EnvVar: "NEO_ACQUISITION_TIMEOUT",
+	})
+	neoCircuitFailures := app.Int(cli.IntOpt{
+		Name:   "neo-circuit-failures",
+		Value:  5,
+		Desc:   "Number of consecutive failed reads opening the circuit of a neo4j cluster, when several are configured",
+		EnvVar: "NEO_CIRCUIT_FAILURES",
+	})
+	neoCircuitOpenFor := app.String(cli.StringOpt{
+		Name:   "neo-circuit-open-for",
+		Value:  "30s",
+		Desc:   "Duration the reads skip a neo4j cluster for once its circuit opened, before probing it again",
+		EnvVar: "NEO_CIRCUIT_OPEN_FOR",
+	})
 	port := app.String(cli.StringOpt{
 		Name:   "port",
 		Value:  "8080",
 		Desc:   "Port to listen on",
 		EnvVar: "PORT",
 	})
+	adminPort := app.String(cli.StringOpt{
+		Name:   "admin-port",
+		Value:  "",
+		Desc:   "Port the health, metrics, diagnostics, config and pprof endpoints are served on, apart from the API. They are served on the API port, without the diagnostics and pprof ones, if empty.",
+		EnvVar: "ADMIN_PORT",
+	})
 	apiURL := app.String(cli.StringOpt{
 		Name:   "publicAPIURL",
 		Value:  "http://api.ft.com",
@@ -70,17 +193,212 @@ func main() {
 		Desc:   "Location of the API Swagger YML file.",
 		EnvVar: "API_YML",
 	})
+	strictMapping := app.Bool(cli.BoolOpt{
+		Name:   "strict-mapping",
+		Value:  false,
+		Desc:   "Fail the request instead of dropping the annotations which cannot be mapped to the response format",
+		EnvVar: "STRICT_MAPPING",
+	})
+	bookmarkReadTimeout := app.String(cli.StringOpt{
+		Name:   "bookmark-read-timeout",
+		Value:  "0s",
+		Desc:   "Transaction timeout of the reads with a Neo4j-Bookmark, e.g. 2s, after which Neo4j terminates them. The transactions are not bounded if zero.",
+		EnvVar: "BOOKMARK_READ_TIMEOUT",
+	})
+	maxInFlightReads := app.Int(cli.IntOpt{
+		Name:   "max-inflight-reads",
+		Value:  0,
+		Desc:   "Maximum number of annotations reads in flight, beyond which the reads are queued. The reads are not limited if zero.",
+		EnvVar: "MAX_INFLIGHT_READS",
+	})
+	readQueueSize := app.Int(cli.IntOpt{
+		Name:   "read-queue-size",
+		Value:  100,
+		Desc:   "Maximum number of reads waiting for the reads in flight to complete, beyond which they are rejected with 503",
+		EnvVar: "READ_QUEUE_SIZE",
+	})
+	readQueueTimeout := app.String(cli.StringOpt{
+		Name:   "read-queue-timeout",
+		Value:  "1s",
+		Desc:   "Maximum duration of a read waiting in the queue before being rejected with 503, which is also its Retry-After",
+		EnvVar: "READ_QUEUE_TIMEOUT",
+	})
+	maxClientReads := app.Int(cli.IntOpt{
+		Name:   "max-inflight-reads-per-client",
+		Value:  0,
+		Desc:   "Maximum number of reads in flight or queued of each client, beyond which they are rejected with 429. The clients are not limited if zero.",
+		EnvVar: "MAX_INFLIGHT_READS_PER_CLIENT",
+	})
+	clientHeader := app.String(cli.StringOpt{
+		Name:   "client-header",
+		Value:  "X-Api-Key",
+		Desc:   "Request header identifying the client for max-inflight-reads-per-client",
+		EnvVar: "CLIENT_HEADER",
+	})
+	backend := app.String(cli.StringOpt{
+		Name:   "backend",
+		Value:  backendNeo4j,
+		Desc:   "Backend the annotations are read from (neo4j, fixtures, replay)",
+		EnvVar: "BACKEND",
+	})
+	fixturesDir := app.String(cli.StringOpt{
+		Name:   "fixtures-dir",
+		Value:  "",
+		Desc:   "Directory of the JSON content, concepts and annotations served by the fixtures backend",
+		EnvVar: "FIXTURES_DIR",
+	})
+	recordingsDir := app.String(cli.StringOpt{
+		Name:   "recordings-dir",
+		Value:  "",
+		Desc:   "Directory the rows read for every content are recorded to, or served from by the replay backend. Nothing is recorded if empty.",
+		EnvVar: "RECORDINGS_DIR",
+	})
+	predicateRules := app.String(cli.StringOpt{
+		Name:   "predicate-rules",
+		Value:  "",
+		Desc:   "YAML or JSON file defining the groups of predicates the rule of importance is applied to. The built-in groups are used if empty.",
+		EnvVar: "PREDICATE_RULES",
+	})
+	lifecyclePolicy := app.String(cli.StringOpt{
+		Name:   "lifecycle-policy",
+		Value:  "",
+		Desc:   "YAML or JSON file defining the annotation lifecycles and the precedence between them. The built-in policy is used if empty.",
+		EnvVar: "LIFECYCLE_POLICY",
+	})
+	publicationRegistry := app.String(cli.StringOpt{
+		Name:   "publication-registry",
+		Value:  "",
+		Desc:   "YAML or JSON file defining the publications and the default publication. The built-in registry is used if empty.",
+		EnvVar: "PUBLICATION_REGISTRY",
+	})
+	runtimeConfig := app.String(cli.StringOpt{
+		Name:   "runtime-config",
+		Value:  "",
+		Desc:   "YAML or JSON file with the reloadable settings overriding their flags, like cacheDuration",
+		EnvVar: "RUNTIME_CONFIG",
+	})
+	adminToken := app.String(cli.StringOpt{
+		Name:   "admin-token",
+		Value:  "",
+		Desc:   "Bearer token authenticating the requests to the admin endpoints. The admin endpoints are disabled if empty.",
+		EnvVar: "ADMIN_TOKEN",
+	})
+	probeThreshold := app.String(cli.StringOpt{
+		Name:   "health-probe-threshold",
+		Value:  "2s",
+		Desc:   "Latency of the neo4j probe query above which the healthcheck reports neo4j as slow",
+		EnvVar: "HEALTH_PROBE_THRESHOLD",
+	})
+	canaryContentUUID := app.String(cli.StringOpt{
+		Name:   "canary-content-uuid",
+		Value:  "",
+		Desc:   "UUID of a content expected to have annotations, which the healthcheck reads. No canary content is read if empty.",
+		EnvVar: "CANARY_CONTENT_UUID",
+	})
+	connectivityInterval := app.String(cli.StringOpt{
+		Name:   "connectivity-check-interval",
+		Value:  "5s",
+		Desc:   "How often the connectivity to neo4j is checked in the background, which /__gtg and /__health serve the latest result of",
+		EnvVar: "CONNECTIVITY_CHECK_INTERVAL",
+	})
+	queryCheckInterval := app.String(cli.StringOpt{
+		Name:   "query-check-interval",
+		Value:  "30s",
+		Desc:   "How often the query latency, schema and canary content healthchecks run in the background",
+		EnvVar: "QUERY_CHECK_INTERVAL",
+	})
+	shutdownDelay := app.String(cli.StringOpt{
+		Name:   "shutdown-delay",
+		Value:  "5s",
+		Desc:   "Duration the requests keep being served on SIGTERM or SIGINT once /__gtg fails, so that the load balancers stop routing requests to the service before it stops accepting them",
+		EnvVar: "SHUTDOWN_DELAY",
+	})
+	shutdownGracePeriod := app.String(cli.StringOpt{
+		Name:   "shutdown-grace-period",
+		Value:  "20s",
+		Desc:   "Maximum duration of waiting for the in-flight requests to complete on SIGTERM or SIGINT, before closing the neo4j driver",
+		EnvVar: "SHUTDOWN_GRACE_PERIOD",
+	})
+	otlpEndpoint := app.String(cli.StringOpt{
+		Name:   "otlp-endpoint",
+		Value:  "",
+		Desc:   "host:port of the OTLP/HTTP collector traces are exported to. Traces are not exported if empty.",
+		EnvVar: "OTLP_ENDPOINT",
+	})
+	otlpInsecure := app.Bool(cli.BoolOpt{
+		Name:   "otlp-insecure",
+		Value:  false,
+		Desc:   "Export traces to the OTLP collector over plain HTTP instead of HTTPS",
+		EnvVar: "OTLP_INSECURE",
+	})
 
 	log := logger.NewUPPLogger(appName, *logLevel)
 	dbDriverLogger := logger.NewUPPLogger(appName+"-cmneo4j-driver", *dbDriverLogLevel)
 
 	app.Action = func() {
-		log.Infof("public-annotations-api will listen on port: %s, connecting to: %s", *port, *neoURL)
-		err := runServer(*neoURL, *port, *cacheDuration, *apiURL, *apiYml, dbDriverLogger, log)
+		log.Infof("public-annotations-api will listen on port: %s, reading annotations from the %s backend", *port, *backend)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		if *otlpEndpoint != "" {
+			tp, err := newTracerProvider(*otlpEndpoint, *otlpInsecure)
+			if err != nil {
+				log.WithError(err).Error("failed to configure trace exporting")
+				return
+			}
+			defer func() {
+				if err := tp.Shutdown(context.Background()); err != nil {
+					log.WithError(err).Error("failed to shut down the tracer provider")
+				}
+			}()
+			otel.SetTracerProvider(tp)
+		}
+
+		cfg := serverConfig{
+			neoURL:                *neoURL,
+			neoCAFile:             *neoCAFile,
+			neoUsername:           *neoUsername,
+			neoPassword:           *neoPassword,
+			neoPasswordFile:       *neoPasswordFile,
+			neoDatabase:           *neoDatabase,
+			neoMaxPoolSize:        *neoMaxPoolSize,
+			neoAcquisitionTimeout: *neoAcquisitionTimeout,
+			neoCircuitFailures:    *neoCircuitFailures,
+			neoCircuitOpenFor:     *neoCircuitOpenFor,
+			port:                  *port,
+			adminPort:             *adminPort,
+			cacheDuration:         *cacheDuration,
+			apiURL:                *apiURL,
+			apiYml:                *apiYml,
+			strictMapping:         *strictMapping,
+			bookmarkReadTimeout:   *bookmarkReadTimeout,
+			maxInFlightReads:      *maxInFlightReads,
+			readQueueSize:         *readQueueSize,
+			readQueueTimeout:      *readQueueTimeout,
+			maxClientReads:        *maxClientReads,
+			clientHeader:          *clientHeader,
+			backend:               *backend,
+			fixturesDir:           *fixturesDir,
+			recordingsDir:         *recordingsDir,
+			predicateRules:        *predicateRules,
+			lifecyclePolicy:       *lifecyclePolicy,
+			publicationRegistry:   *publicationRegistry,
+			runtimeConfig:         *runtimeConfig,
+			adminToken:            *adminToken,
+			probeThreshold:        *probeThreshold,
+			canaryContentUUID:     *canaryContentUUID,
+			connectivityInterval:  *connectivityInterval,
+			queryCheckInterval:    *queryCheckInterval,
+			shutdownDelay:         *shutdownDelay,
+			shutdownGracePeriod:   *shutdownGracePeriod,
+		}
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+		defer signal.Stop(stop)
+		err := runServer(cfg, dbDriverLogger, log, stop)
 		if err != nil {
 			log.WithError(err).Error("failed to start public-annotations-api service")
 			return
 		}
+		log.Info("public-annotations-api stopped")
 	}
 
 	log.Infof("Application started with args %s", os.Args)
@@ -91,60 +409,476 @@ func main() {
 	}
 }
 
-func runServer(neoURL, port, cacheDuration, apiURL, apiYml string, dbDriverLogger, log *logger.UPPLogger) error {
-	duration, durationErr := time.ParseDuration(cacheDuration)
-	if durationErr != nil {
-		return fmt.Errorf("failed to parse cache duration string: %w", durationErr)
+// runServer serves until stop receives a signal, then shuts down gracefully.
+func runServer(cfg serverConfig, dbDriverLogger, log *logger.UPPLogger, stop <-chan os.Signal) error {
+	settings, err := loadSettings(cfg, log)
+	if err != nil {
+		return err
+	}
+	readLimiter, err := newReadLimiter(cfg)
+	if err != nil {
+		return err
+	}
+
+	handlersCtx, drivers, err := newHandlerCtx(cfg, settings, dbDriverLogger, log)
+	if err != nil {
+		return err
+	}
+	handlersCtx.ReadLimiter = readLimiter
+	if cfg.recordingsDir != "" && cfg.backend != backendReplay {
+		log.Infof("recording the annotations read to: %s", cfg.recordingsDir)
+		handlersCtx.AnnotationsDriver = annotations.NewRecordingDriver(handlersCtx.AnnotationsDriver, cfg.recordingsDir, log)
+	}
+
+	load := func() (*annotations.Settings, error) {
+		return loadSettings(cfg, log)
+	}
+	go reloadOnSignal(handlersCtx, load, log)
+
+	return routeRequests(cfg, handlersCtx, load, drivers, stop)
+}
+
+func closeDrivers(drivers []io.Closer, log *logger.UPPLogger) {
+	for _, d := range drivers {
+		if err := d.Close(); err != nil {
+			log.WithError(err).Error("failed closing the neo4j driver")
+		}
 	}
-	cacheControlHeader := fmt.Sprintf("max-age=%s, public", strconv.FormatFloat(duration.Seconds(), 'f', 0, 64))
+}
 
-	driver, err := cmneo4j.NewDefaultDriver(neoURL, dbDriverLogger)
+// newReadLimiter limits the concurrent reads as configured, or returns nil if they are not limited.
+func newReadLimiter(cfg serverConfig) (*annotations.ReadLimiter, error) {
+	if cfg.maxInFlightReads <= 0 {
+		return nil, nil
+	}
+	if cfg.readQueueSize < 0 {
+		return nil, fmt.Errorf("invalid read queue size %d", cfg.readQueueSize)
+	}
+	queueTimeout, err := time.ParseDuration(cfg.readQueueTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read queue timeout %q: %w", cfg.readQueueTimeout, err)
+	}
+	if cfg.readQueueSize > 0 && queueTimeout <= 0 {
+		return nil, fmt.Errorf("invalid read queue timeout %q: it has to be positive with a read queue", cfg.readQueueTimeout)
+	}
+	return annotations.NewReadLimiter(cfg.maxInFlightReads,
+		annotations.WithReadQueue(cfg.readQueueSize, queueTimeout),
+		annotations.WithClientLimit(cfg.clientHeader, cfg.maxClientReads)), nil
+}
+
+// loadSettings loads the reloadable settings from the flags and the configuration files.
+func loadSettings(cfg serverConfig, log *logger.UPPLogger) (*annotations.Settings, error) {
+	cacheDuration := cfg.cacheDuration
+	if cfg.runtimeConfig != "" {
+		runtimeConfig, err := annotations.LoadRuntimeConfig(cfg.runtimeConfig)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("loaded runtime config from: %s", cfg.runtimeConfig)
+		if runtimeConfig.CacheDuration != "" {
+			cacheDuration = runtimeConfig.CacheDuration
+		}
+	}
+	cacheControlHeader, err := annotations.CacheControlHeader(cacheDuration)
 	if err != nil {
-		return fmt.Errorf("could not create a new driver: %w", err)
+		return nil, err
 	}
+	settings := &annotations.Settings{CacheControlHeader: cacheControlHeader}
 
-	annotationsDriver := annotations.NewCypherDriver(driver, apiURL)
-	handlersCtx := annotations.NewHandlerCtx(annotationsDriver, cacheControlHeader, log)
-	return routeRequests(port, handlersCtx, apiYml)
+	if cfg.predicateRules != "" {
+		rules, err := annotations.LoadPredicateRules(cfg.predicateRules)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("loaded predicate rules from: %s", cfg.predicateRules)
+		settings.PredicateRules = rules
+	}
+
+	if cfg.lifecyclePolicy != "" {
+		policy, err := annotations.LoadLifecyclePolicy(cfg.lifecyclePolicy)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("loaded lifecycle policy from: %s", cfg.lifecyclePolicy)
+		settings.LifecyclePolicy = policy
+	}
+
+	if cfg.publicationRegistry != "" {
+		registry, err := annotations.LoadPublicationRegistry(cfg.publicationRegistry)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("loaded publication registry from: %s", cfg.publicationRegistry)
+		settings.PublicationRegistry = registry
+	}
+
+	return settings, nil
 }
 
-func routeRequests(port string, hctx *annotations.HandlerCtx, apiYml string) error {
+// reloadOnSignal reloads the settings every time the process receives a SIGHUP.
+func reloadOnSignal(hctx *annotations.HandlerCtx, load func() (*annotations.Settings, error), log *logger.UPPLogger) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	for range sighup {
+		log.Info("received SIGHUP, reloading settings")
+		// failures are logged by ReloadSettings
+		_, _ = hctx.ReloadSettings(load)
+	}
+}
+
+// newHandlerCtx creates the handlers context reading the annotations from the configured backend,
+// along with the neo4j drivers to close on shutdown.
+func newHandlerCtx(cfg serverConfig, settings *annotations.Settings, dbDriverLogger, log *logger.UPPLogger) (*annotations.HandlerCtx, []io.Closer, error) {
+	switch cfg.backend {
+	case backendNeo4j:
+		bookmarkReadTimeout, err := time.ParseDuration(cfg.bookmarkReadTimeout)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid bookmark read timeout %q: %w", cfg.bookmarkReadTimeout, err)
+		}
+		backends, err := parseNeoURLs(cfg.neoURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		var drivers []io.Closer
+		newCypherDriver := func(neoURL string) (annotations.CypherDriver, error) {
+			log.Infof("connecting to: %s", neoURL)
+			driver, err := newNeo4jDriver(cfg, neoURL, dbDriverLogger)
+			if err != nil {
+				return annotations.CypherDriver{}, fmt.Errorf("could not create a new driver: %w", err)
+			}
+			drivers = append(drivers, driver)
+			return annotations.NewCypherDriver(driver, cfg.apiURL,
+				annotations.WithStrictMapping(cfg.strictMapping),
+				annotations.WithBookmarkReadTimeout(bookmarkReadTimeout)), nil
+		}
+		if len(backends) == 1 {
+			annotationsDriver, err := newCypherDriver(backends[0].url)
+			if err != nil {
+				return nil, nil, err
+			}
+			return annotations.NewHandlerCtx(annotationsDriver, settings, log), drivers, nil
+		}
+
+		if cfg.neoCircuitFailures <= 0 {
+			return nil, nil, fmt.Errorf("invalid neo4j circuit failures %d", cfg.neoCircuitFailures)
+		}
+		circuitOpenFor, err := time.ParseDuration(cfg.neoCircuitOpenFor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid neo4j circuit open duration %q: %w", cfg.neoCircuitOpenFor, err)
+		}
+		opts := []func(*annotations.FailoverDriver){annotations.WithCircuitBreaker(cfg.neoCircuitFailures, circuitOpenFor)}
+		for _, b := range backends {
+			cypherDriver, err := newCypherDriver(b.url)
+			if err != nil {
+				return nil, nil, fmt.Errorf("neo4j backend %s: %w", b.name, err)
+			}
+			opts = append(opts, annotations.WithFailoverBackend(b.name, cypherDriver))
+		}
+		return annotations.NewHandlerCtx(annotations.NewFailoverDriver(log, opts...), settings, log), drivers, nil
+	case backendFixtures:
+		if cfg.fixturesDir == "" {
+			return nil, nil, fmt.Errorf("the %s backend requires a fixtures directory", backendFixtures)
+		}
+		log.Infof("loading fixtures from: %s", cfg.fixturesDir)
+		annotationsDriver, err := annotations.NewFixturesDriver(cfg.fixturesDir, cfg.apiURL,
+			annotations.WithFixturesStrictMapping(cfg.strictMapping), annotations.WithFixturesLogger(log))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not load the fixtures: %w", err)
+		}
+		return annotations.NewHandlerCtx(annotationsDriver, settings, log), nil, nil
+	case backendReplay:
+		if cfg.recordingsDir == "" {
+			return nil, nil, fmt.Errorf("the %s backend requires a recordings directory", backendReplay)
+		}
+		log.Infof("replaying recordings from: %s", cfg.recordingsDir)
+		annotationsDriver := annotations.NewReplayDriver(cfg.recordingsDir, cfg.apiURL, annotations.WithReplayStrictMapping(cfg.strictMapping))
+		return annotations.NewHandlerCtx(annotationsDriver, settings, log), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown backend %q", cfg.backend)
+	}
+}
+
+// neoBackend is a neo4j cluster the annotations are read from, named after the host of its URL.
+type neoBackend struct {
+	name string
+	url  string
+}
+
+// parseNeoURLs parses the comma-separated neo4j URLs, in order of preference.
+func parseNeoURLs(neoURLs string) ([]neoBackend, error) {
+	var backends []neoBackend
+	seen := map[string]bool{}
+	for _, neoURL := range strings.Split(neoURLs, ",") {
+		neoURL = strings.TrimSpace(neoURL)
+		if neoURL == "" {
+			continue
+		}
+		u, err := url.Parse(neoURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid neo4j url %q", neoURL)
+		}
+		if seen[u.Host] {
+			return nil, fmt.Errorf("the neo4j url of %s is repeated", u.Host)
+		}
+		seen[u.Host] = true
+		backends = append(backends, neoBackend{name: u.Host, url: neoURL})
+	}
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("no neo4j url configured")
+	}
+	return backends, nil
+}
+
+// newNeo4jDriver connects to neo4j with the configured credentials, database, TLS trust and connection pool.
+// The annotation reads and the connectivity checks share the driver, so they use the same settings.
+func newNeo4jDriver(cfg serverConfig, neoURL string, log *logger.UPPLogger) (*annotations.Neo4jDriver, error) {
+	configure, err := neo4jConfig(cfg, neoURL)
+	if err != nil {
+		return nil, err
+	}
+	opts := []func(*annotations.Neo4jDriver){annotations.WithNeo4jConfig(configure)}
+
+	username, password, err := neo4jCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if username != "" {
+		opts = append(opts, annotations.WithNeo4jAuth(username, password))
+	}
+	if cfg.neoDatabase != "" {
+		opts = append(opts, annotations.WithNeo4jDatabase(cfg.neoDatabase))
+	}
+
+	return annotations.NewNeo4jDriver(neoURL, log, opts...)
+}
+
+// neo4jConfig configures the TLS trust and the connection pool of the neo4j driver connecting to neoURL.
+func neo4jConfig(cfg serverConfig, neoURL string) (func(*neo4j.Config), error) {
+	if cfg.neoMaxPoolSize <= 0 {
+		return nil, fmt.Errorf("invalid neo4j max pool size %d", cfg.neoMaxPoolSize)
+	}
+	acquisitionTimeout, err := time.ParseDuration(cfg.neoAcquisitionTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid neo4j acquisition timeout %q: %w", cfg.neoAcquisitionTimeout, err)
+	}
+
+	var rootCAs *x509.CertPool
+	if cfg.neoCAFile != "" {
+		// the +ssc schemes trust any certificate, so a CA bundle only makes sense with the +s ones
+		if scheme, _, _ := strings.Cut(neoURL, "://"); !strings.HasSuffix(scheme, "+s") {
+			return nil, fmt.Errorf("the neo4j CA file requires a neo4j+s:// or bolt+s:// url, got %s", neoURL)
+		}
+		pem, err := os.ReadFile(cfg.neoCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the neo4j CA file: %w", err)
+		}
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in the neo4j CA file %s", cfg.neoCAFile)
+		}
+	}
+
+	return func(config *neo4j.Config) {
+		config.MaxConnectionPoolSize = cfg.neoMaxPoolSize
+		config.ConnectionAcquisitionTimeout = acquisitionTimeout
+		if rootCAs != nil {
+			config.RootCAs = rootCAs
+		}
+	}, nil
+}
+
+// neo4jCredentials returns the username and password authenticating to neo4j, reading the password from its file if set.
+// An empty username means the connections are not authenticated.
+func neo4jCredentials(cfg serverConfig) (string, string, error) {
+	if cfg.neoPassword != "" && cfg.neoPasswordFile != "" {
+		return "", "", errors.New("only one of the neo4j password and password file can be set")
+	}
+	password := cfg.neoPassword
+	if cfg.neoPasswordFile != "" {
+		data, err := os.ReadFile(cfg.neoPasswordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("failed reading the neo4j password file: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+	if cfg.neoUsername == "" && password != "" {
+		return "", "", errors.New("the neo4j password requires a neo4j username")
+	}
+	return cfg.neoUsername, password, nil
+}
+
+func newTracerProvider(endpoint string, insecure bool) (*sdktrace.TracerProvider, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(appName)))
+	if err != nil {
+		return nil, fmt.Errorf("could not create tracing resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res)), nil
+}
+
+// parseInterval parses the interval of a background check, which has to be positive.
+func parseInterval(interval string) (time.Duration, error) {
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s is not positive", interval)
+	}
+	return d, nil
+}
+
+// routeRequests serves the endpoints until stop receives a signal. The drivers are closed once it returns,
+// after the in-flight requests and the health checks using them are done.
+func routeRequests(cfg serverConfig, hctx *annotations.HandlerCtx, load func() (*annotations.Settings, error), drivers []io.Closer, stop <-chan os.Signal) error {
+	defer closeDrivers(drivers, hctx.Log)
+
+	probeThreshold, err := time.ParseDuration(cfg.probeThreshold)
+	if err != nil {
+		return fmt.Errorf("invalid health probe threshold %q: %w", cfg.probeThreshold, err)
+	}
+	connectivityInterval, err := parseInterval(cfg.connectivityInterval)
+	if err != nil {
+		return fmt.Errorf("invalid connectivity check interval: %w", err)
+	}
+	queryCheckInterval, err := parseInterval(cfg.queryCheckInterval)
+	if err != nil {
+		return fmt.Errorf("invalid query check interval: %w", err)
+	}
+	healthCfg := annotations.HealthConfig{
+		ProbeThreshold:       probeThreshold,
+		CanaryContentUUID:    cfg.canaryContentUUID,
+		ConnectivityInterval: connectivityInterval,
+		QueryInterval:        queryCheckInterval,
+	}
+	if cfg.adminPort == cfg.port {
+		return fmt.Errorf("the admin port has to differ from the port %s", cfg.port)
+	}
+	shutdownDelay, err := time.ParseDuration(cfg.shutdownDelay)
+	if err != nil || shutdownDelay < 0 {
+		return fmt.Errorf("invalid shutdown delay %q", cfg.shutdownDelay)
+	}
+	gracePeriod, err := time.ParseDuration(cfg.shutdownGracePeriod)
+	if err != nil {
+		return fmt.Errorf("invalid shutdown grace period %q: %w", cfg.shutdownGracePeriod, err)
+	}
+	scheduler := annotations.NewHealthScheduler()
+	checks, goodToGo := annotations.ScheduleHealthChecks(scheduler, hctx, healthCfg)
+	ctx, stopScheduler := context.WithCancel(context.Background())
+	scheduler.Start(ctx)
+	// the checks use the drivers, so they are stopped before the drivers are closed
+	defer func() {
+		stopScheduler()
+		scheduler.Wait()
+	}()
+
+	// the service is no longer good to go once shutting down, so that no new requests are routed to it
+	var shuttingDown atomic.Bool
+	goodToGoUnlessShuttingDown := func() gtg.Status {
+		if shuttingDown.Load() {
+			return gtg.Status{GoodToGo: false, Message: "shutting down"}
+		}
+		return goodToGo()
+	}
+
 	// Standard endpoints
 	healthCheck := fthealth.TimedHealthCheck{
 		HealthCheck: fthealth.HealthCheck{
 			SystemCode:  "annotationsapi",
 			Name:        "public-annotations-api",
 			Description: appDescription,
-			Checks: []fthealth.Check{
-				annotations.HealthCheck(hctx),
-			},
+			Checks:      checks,
 		},
 		Timeout: 10 * time.Second,
 	}
-	http.HandleFunc("/__health", fthealth.Handler(healthCheck))
-	http.HandleFunc(status.GTGPath, status.NewGoodToGoHandler(annotations.GoodToGo(hctx)))
-	http.HandleFunc(status.BuildInfoPath, status.BuildInfoHandler)
+	adminMux := http.NewServeMux()
+	adminMux.HandleFunc("/__health", fthealth.Handler(healthCheck))
+	adminMux.HandleFunc(status.GTGPath, status.NewGoodToGoHandler(goodToGoUnlessShuttingDown))
+	adminMux.HandleFunc(status.BuildInfoPath, status.BuildInfoHandler)
+	adminMux.Handle("/metrics", promhttp.Handler())
+	adminMux.HandleFunc("GET /__config", annotations.GetConfig(hctx))
+	if cfg.adminToken != "" {
+		adminMux.HandleFunc("POST /__reload", annotations.PostReload(hctx, cfg.adminToken, load))
+	}
 
 	// API specific endpoints
 	servicesRouter := mux.NewRouter()
 
 	servicesRouter.HandleFunc("/content/{uuid}/annotations", annotations.GetAnnotations(hctx)).Methods("GET")
 	servicesRouter.HandleFunc("/content/{uuid}/annotations", annotations.MethodNotAllowedHandler)
-	if apiYml != "" {
-		if endpoint, err := apiEndpoint.NewAPIEndpointForFile(apiYml); err == nil {
+	if cfg.apiYml != "" {
+		if endpoint, err := apiEndpoint.NewAPIEndpointForFile(cfg.apiYml); err == nil {
 			servicesRouter.HandleFunc(apiEndpoint.DefaultPath, endpoint.ServeHTTP).Methods("GET")
 		}
 	}
 
+	// the debug endpoints are only served on the admin port, which can be locked down at the network level
+	if cfg.adminPort != "" {
+		diagnosticsRouter := mux.NewRouter()
+		diagnosticsRouter.HandleFunc("/__diagnostics/content/{uuid}", annotations.GetContentDiagnostics(hctx)).Methods("GET")
+		var diagnosticsHandler http.Handler = diagnosticsRouter
+		diagnosticsHandler = httphandlers.TransactionAwareRequestLoggingHandler(hctx.Log, diagnosticsHandler)
+		diagnosticsHandler = httphandlers.HTTPMetricsHandler(metrics.DefaultRegistry, diagnosticsHandler)
+		adminMux.Handle("/__diagnostics/", diagnosticsHandler)
+		adminMux.HandleFunc("/debug/pprof/", pprof.Index)
+		adminMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		adminMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		adminMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		adminMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
 	var monitoringRouter http.Handler = servicesRouter
 	monitoringRouter = httphandlers.TransactionAwareRequestLoggingHandler(hctx.Log, monitoringRouter)
 	monitoringRouter = httphandlers.HTTPMetricsHandler(metrics.DefaultRegistry, monitoringRouter)
 
-	http.Handle("/", monitoringRouter)
+	servers := []*http.Server{{Addr: ":" + cfg.port, Handler: adminMux}}
+	if cfg.adminPort != "" {
+		publicMux := http.NewServeMux()
+		publicMux.Handle("/", monitoringRouter)
+		servers = []*http.Server{{Addr: ":" + cfg.port, Handler: publicMux}, {Addr: ":" + cfg.adminPort, Handler: adminMux}}
+	} else {
+		adminMux.Handle("/", monitoringRouter)
+	}
+
+	return serveUntilSignal(stop, servers, shutdownDelay, gracePeriod, func() { shuttingDown.Store(true) }, hctx.Log)
+}
 
-	err := http.ListenAndServe(":"+port, nil)
-	if err != nil {
+// serveUntilSignal serves until stop receives a signal. It then calls onShutdown and keeps serving for the shutdown delay,
+// so that the load balancers notice the service is shutting down, before it stops accepting new connections and waits
+// up to the grace period for the in-flight requests to complete.
+func serveUntilSignal(stop <-chan os.Signal, servers []*http.Server, shutdownDelay, gracePeriod time.Duration, onShutdown func(), log *logger.UPPLogger) error {
+	served := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func() {
+			served <- srv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-served:
 		return fmt.Errorf("failed to start server: %w", err)
+	case sig := <-stop:
+		log.Infof("received %s, serving for %s before draining the in-flight requests for up to %s", sig, shutdownDelay, gracePeriod)
+	}
+
+	onShutdown()
+	time.Sleep(shutdownDelay)
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed draining the in-flight requests of %s: %w", srv.Addr, err))
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/go-logger/v2"
+	"github.com/Financial-Times/public-annotations-api/v3/annotations"
+	status "github.com/Financial-Times/service-status-go/httphandlers"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNeoURLs(t *testing.T) {
+	tests := map[string]struct {
+		neoURLs  string
+		expected []neoBackend
+		err      string
+	}{
+		"single url": {
+			neoURLs:  "bolt://localhost:7687",
+			expected: []neoBackend{{name: "localhost:7687", url: "bolt://localhost:7687"}},
+		},
+		"urls in order of preference": {
+			neoURLs: " neo4j+s://primary:7687, ,neo4j+s://secondary:7687,",
+			expected: []neoBackend{
+				{name: "primary:7687", url: "neo4j+s://primary:7687"},
+				{name: "secondary:7687", url: "neo4j+s://secondary:7687"},
+			},
+		},
+		"no url": {
+			neoURLs: " , ",
+			err:     "no neo4j url configured",
+		},
+		"url without host": {
+			neoURLs: "bolt://localhost:7687,localhost",
+			err:     `invalid neo4j url "localhost"`,
+		},
+		"repeated host": {
+			neoURLs: "bolt://localhost:7687,neo4j://localhost:7687",
+			err:     "the neo4j url of localhost:7687 is repeated",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			backends, err := parseNeoURLs(test.neoURLs)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, backends)
+		})
+	}
+}
+
+func TestNeo4jConfig(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+	notPEM := filepath.Join(dir, "ca.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	cfg := serverConfig{neoMaxPoolSize: 10, neoAcquisitionTimeout: "5s"}
+	configure, err := neo4jConfig(cfg, "bolt://localhost:7687")
+	require.NoError(t, err)
+	config := neo4j.Config{}
+	configure(&config)
+	assert.Equal(t, 10, config.MaxConnectionPoolSize)
+	assert.Equal(t, 5*time.Second, config.ConnectionAcquisitionTimeout)
+	assert.Nil(t, config.RootCAs)
+
+	cfg.neoCAFile = caFile
+	configure, err = neo4jConfig(cfg, "neo4j+s://localhost:7687")
+	require.NoError(t, err)
+	config = neo4j.Config{}
+	configure(&config)
+	assert.NotNil(t, config.RootCAs)
+
+	tests := map[string]struct {
+		cfg    serverConfig
+		neoURL string
+		err    string
+	}{
+		"invalid pool size": {
+			cfg: serverConfig{neoMaxPoolSize: 0, neoAcquisitionTimeout: "5s"},
+			err: "invalid neo4j max pool size 0",
+		},
+		"invalid acquisition timeout": {
+			cfg: serverConfig{neoMaxPoolSize: 10, neoAcquisitionTimeout: "soon"},
+			err: `invalid neo4j acquisition timeout "soon"`,
+		},
+		"CA file without TLS": {
+			cfg:    serverConfig{neoMaxPoolSize: 10, neoAcquisitionTimeout: "5s", neoCAFile: caFile},
+			neoURL: "neo4j+ssc://localhost:7687",
+			err:    "the neo4j CA file requires a neo4j+s:// or bolt+s:// url, got neo4j+ssc://localhost:7687",
+		},
+		"missing CA file": {
+			cfg: serverConfig{neoMaxPoolSize: 10, neoAcquisitionTimeout: "5s", neoCAFile: filepath.Join(dir, "missing.pem")},
+			err: "failed reading the neo4j CA file",
+		},
+		"CA file without certificates": {
+			cfg: serverConfig{neoMaxPoolSize: 10, neoAcquisitionTimeout: "5s", neoCAFile: notPEM},
+			err: "no certificates found in the neo4j CA file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			neoURL := test.neoURL
+			if neoURL == "" {
+				neoURL = "bolt+s://localhost:7687"
+			}
+			_, err := neo4jConfig(test.cfg, neoURL)
+			assert.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestNeo4jCredentials(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("from-file\n"), 0o600))
+
+	tests := map[string]struct {
+		cfg              serverConfig
+		expectedUsername string
+		expectedPassword string
+		err              string
+	}{
+		"no authentication": {},
+		"password": {
+			cfg:              serverConfig{neoUsername: "reader", neoPassword: "secret"},
+			expectedUsername: "reader",
+			expectedPassword: "secret",
+		},
+		"password file": {
+			cfg:              serverConfig{neoUsername: "reader", neoPasswordFile: passwordFile},
+			expectedUsername: "reader",
+			expectedPassword: "from-file",
+		},
+		"password and password file": {
+			cfg: serverConfig{neoUsername: "reader", neoPassword: "secret", neoPasswordFile: passwordFile},
+			err: "only one of the neo4j password and password file can be set",
+		},
+		"missing password file": {
+			cfg: serverConfig{neoUsername: "reader", neoPasswordFile: filepath.Join(dir, "missing")},
+			err: "failed reading the neo4j password file",
+		},
+		"password without username": {
+			cfg: serverConfig{neoPassword: "secret"},
+			err: "the neo4j password requires a neo4j username",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			username, password, err := neo4jCredentials(test.cfg)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedUsername, username)
+			assert.Equal(t, test.expectedPassword, password)
+		})
+	}
+}
+
+func TestNewNeo4jDriver(t *testing.T) {
+	log := logger.NewUPPLogger("test", "ERROR")
+	cfg := serverConfig{
+		neoMaxPoolSize:        10,
+		neoAcquisitionTimeout: "5s",
+		neoUsername:           "reader",
+		neoPassword:           "secret",
+		neoDatabase:           "annotations",
+	}
+
+	// creating the driver does not connect to neo4j
+	driver, err := newNeo4jDriver(cfg, "bolt://localhost:7687", log)
+	require.NoError(t, err)
+	assert.NoError(t, driver.Close())
+
+	cfg.neoPassword = ""
+	cfg.neoUsername = ""
+	cfg.neoPasswordFile = "missing"
+	_, err = newNeo4jDriver(cfg, "bolt://localhost:7687", log)
+	assert.ErrorContains(t, err, "failed reading the neo4j password file")
+}
+
+func TestNewReadLimiter(t *testing.T) {
+	tests := map[string]struct {
+		cfg           serverConfig
+		expectedError string
+	}{
+		"no limit":               {cfg: serverConfig{readQueueTimeout: "0s"}},
+		"queue":                  {cfg: serverConfig{maxInFlightReads: 2, readQueueSize: 5, readQueueTimeout: "1s"}},
+		"no queue":               {cfg: serverConfig{maxInFlightReads: 2, readQueueTimeout: "0s"}},
+		"negative queue size":    {cfg: serverConfig{maxInFlightReads: 2, readQueueSize: -1, readQueueTimeout: "1s"}, expectedError: "invalid read queue size"},
+		"invalid queue timeout":  {cfg: serverConfig{maxInFlightReads: 2, readQueueSize: 5, readQueueTimeout: "soon"}, expectedError: "invalid read queue timeout"},
+		"zero queue timeout":     {cfg: serverConfig{maxInFlightReads: 2, readQueueSize: 5, readQueueTimeout: "0s"}, expectedError: "has to be positive"},
+		"negative queue timeout": {cfg: serverConfig{maxInFlightReads: 2, readQueueSize: 5, readQueueTimeout: "-1s"}, expectedError: "has to be positive"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := newReadLimiter(test.cfg)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// closerFunc is a driver closed by calling the function.
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestRouteRequestsShutdown(t *testing.T) {
+	log := logger.NewUPPLogger("test-public-annotations-api", "PANIC")
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, listener.Close())
+
+	cfg := serverConfig{
+		port:                 port,
+		cacheDuration:        "30s",
+		backend:              backendFixtures,
+		fixturesDir:          "annotations/testdata",
+		probeThreshold:       "2s",
+		connectivityInterval: "1m",
+		queryCheckInterval:   "1m",
+		shutdownDelay:        "300ms",
+		shutdownGracePeriod:  "1s",
+	}
+	settings, err := loadSettings(cfg, log)
+	require.NoError(t, err)
+	hctx, _, err := newHandlerCtx(cfg, settings, log, log)
+	require.NoError(t, err)
+
+	get := func(path string) int {
+		resp, err := http.Get("http://localhost:" + port + path)
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode
+	}
+
+	closed := make(chan struct{})
+	var servingOnClose int
+	driver := closerFunc(func() error {
+		servingOnClose = get("/__gtg")
+		close(closed)
+		return nil
+	})
+
+	stop := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() {
+		load := func() (*annotations.Settings, error) { return settings, nil }
+		done <- routeRequests(cfg, hctx, load, []io.Closer{driver}, stop)
+	}()
+	require.Eventually(t, func() bool { return get("/__gtg") == http.StatusOK }, 5*time.Second, 10*time.Millisecond)
+
+	stop <- syscall.SIGTERM
+	// the gtg fails while the requests are still served during the shutdown delay
+	require.Eventually(t, func() bool { return get("/__gtg") == http.StatusServiceUnavailable }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, http.StatusOK, get(status.BuildInfoPath))
+	select {
+	case <-closed:
+		t.Fatal("the drivers were closed while serving the requests")
+	default:
+	}
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not shut down")
+	}
+	// the servers were shut down before the drivers were closed
+	assert.Equal(t, 0, servingOnClose)
+	select {
+	case <-closed:
+	default:
+		t.Fatal("the drivers were not closed")
+	}
+}